@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// AccessLogFormat selects how processLog renders the records coming off
+// logCh. The zero value keeps today's free-form key=value lines; the others
+// are the usual web server access log formats.
+type AccessLogFormat string
+
+const (
+	KVFormat       AccessLogFormat = ""
+	CLFFormat      AccessLogFormat = "clf"
+	CombinedFormat AccessLogFormat = "combined"
+	JSONFormat     AccessLogFormat = "json"
+)
+
+var (
+	accessLogFormat  = flag.String("access-log-format", "", "access log format for completed requests: clf, combined or json (default: the general kv log, to stderr)")
+	accessLogFile    = flag.String("access-log-file", "", "file to write the access log to (default: stderr)")
+	accessLogMaxSize = flag.Int64("access-log-max-size", 100<<20, "rotate -access-log-file once it exceeds this many bytes")
+
+	// accessLogDropped counts records lost because logCh was full; surfaced
+	// on the /api endpoint so operators can tell they're losing records.
+	accessLogDropped uint64
+)
+
+// sendLog enqueues a log record without blocking: under load, a full logCh
+// drops the record rather than stalling the request that produced it.
+func sendLog(l Loggable) {
+	select {
+	case logCh <- l:
+	default:
+		atomic.AddUint64(&accessLogDropped, 1)
+	}
+}
+
+// sendAccessLog is sendLog with per-host sampling applied, as configured by
+// a backend's access-log-sample annotation.
+func sendAccessLog(entry *AccessLogEntry, sampleRate uint32) {
+	if sampleRate > 1 && !sampleKeep(entry, sampleRate) {
+		return
+	}
+
+	sendLog(entry)
+}
+
+// sampleKeep deterministically keeps 1 in sampleRate requests for a given
+// host, so a given client's requests aren't disproportionately kept or
+// dropped depending on how many other hosts are sampled.
+func sampleKeep(entry *AccessLogEntry, sampleRate uint32) bool {
+	h := fnv.New32a()
+	h.Write([]byte(entry.Request.ID))
+	return h.Sum32()%sampleRate == 0
+}
+
+func accessLogDroppedCount() uint64 {
+	return atomic.LoadUint64(&accessLogDropped)
+}
+
+func processLog() {
+	format := AccessLogFormat(*accessLogFormat)
+
+	out, err := newAccessLogWriter(*accessLogFile, *accessLogMaxSize)
+	if err != nil {
+		log.Fatal("access-log: failed to open ", *accessLogFile, ": ", err)
+	}
+
+	buf := &bytes.Buffer{}
+
+	for loggable := range logCh {
+		entry, isAccessEntry := loggable.(*AccessLogEntry)
+
+		if format != KVFormat && !isAccessEntry {
+			// clf/combined/json only make sense for full request records
+			continue
+		}
+
+		switch format {
+		case CLFFormat:
+			writeCLF(out, entry, false)
+		case CombinedFormat:
+			writeCLF(out, entry, true)
+		case JSONFormat:
+			writeJSON(out, entry)
+		default:
+			loggable.ToLog((*LogMessage)(buf))
+			fmt.Fprintln(out, buf.String())
+			buf.Reset()
+		}
+	}
+}
+
+func writeCLF(w io.Writer, e *AccessLogEntry, combined bool) {
+	remote := e.Remote
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+
+	line := fmt.Sprintf("%s - - [%s] %q %d %d",
+		remote,
+		e.Request.start.Format("02/Jan/2006:15:04:05 -0700"),
+		fmt.Sprintf("%s %s %s", e.Method, e.URI, e.HTTPVersion),
+		e.Status,
+		e.BytesOut,
+	)
+
+	if combined {
+		line += fmt.Sprintf(" %q %q", e.Referer, e.UserAgent)
+	}
+
+	fmt.Fprintln(w, line)
+}
+
+func writeJSON(w io.Writer, e *AccessLogEntry) {
+	json.NewEncoder(w).Encode(map[string]any{
+		"time":         e.Request.start,
+		"request-id":   e.Request.ID,
+		"remote":       e.Remote,
+		"proto":        e.Proto,
+		"host":         e.Host,
+		"method":       e.Method,
+		"uri":          e.URI,
+		"http-version": e.HTTPVersion,
+		"tls-sni":      e.TLSServerName,
+		"tls-version":  e.TLSVersion,
+		"ingress":      e.Ingress,
+		"target":       e.Target,
+		"reject":       e.Reject,
+		"status":       e.Status,
+		"bytes-in":     e.BytesIn,
+		"bytes-out":    e.BytesOut,
+		"connect-time": e.ConnectTime.Seconds(),
+		"duration":     e.Duration.Seconds(),
+		"referer":      e.Referer,
+		"user-agent":   e.UserAgent,
+	})
+}
+
+// accessLogWriter wraps the destination file, rotating it (by renaming the
+// current file aside with a ".1" suffix and reopening) once it grows past
+// maxSize. maxSize <= 0 disables rotation.
+type accessLogWriter struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	size    int64
+	f       *os.File
+}
+
+func newAccessLogWriter(path string, maxSize int64) (io.Writer, error) {
+	if path == "" {
+		return os.Stderr, nil
+	}
+
+	w := &accessLogWriter{path: path, maxSize: maxSize}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (w *accessLogWriter) open() error {
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	w.f = f
+	w.size = info.Size()
+	return nil
+}
+
+func (w *accessLogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.maxSize > 0 && w.size >= w.maxSize {
+		w.f.Close()
+		os.Rename(w.path, w.path+".1")
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.f.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func tlsVersionName(version uint16) string {
+	switch version {
+	case 0x0301:
+		return "TLS1.0"
+	case 0x0302:
+		return "TLS1.1"
+	case 0x0303:
+		return "TLS1.2"
+	case 0x0304:
+		return "TLS1.3"
+	default:
+		return fmt.Sprintf("0x%04x", version)
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+
+	"github.com/mcluseau/kingress/config"
+	"github.com/mcluseau/kingress/k8s"
+	"github.com/mcluseau/kingress/kubeclient"
+)
+
+var (
+	acmeEnabled = flag.Bool("acme-enabled", false,
+		`Enable automatic ACME certificate issuance for ingress TLS hosts carrying the kingress.mcluseau.github.io/acme: "true" annotation.`)
+	acmeDirectoryURL = flag.String("acme-directory-url", autocert.DefaultACMEDirectory,
+		"ACME directory URL used for automatic certificate issuance.")
+	acmeEmail = flag.String("acme-email", "",
+		"Contact email given to the ACME CA on account registration.")
+	acmeRenewBefore = flag.Duration("acme-renew-before", 0,
+		"How long before expiration an ACME certificate is renewed (0 uses autocert's default of 30 days).")
+)
+
+// acmeManager is nil unless -acme-enabled is set, in which case it backs
+// both getCertificate's on-demand issuance and acmeHTTPHandler's HTTP-01
+// responder.
+//
+// Only the http-01 challenge type is supported: tls-alpn-01 would need the
+// https listener to advertise "acme-tls/1" in its NextProtos, and dns-01
+// would need a provider-specific DNS API client, neither of which this
+// package has a hook for yet.
+var acmeManager *autocert.Manager
+
+// startACME builds acmeManager once flags are parsed; called from main
+// before the HTTP/HTTPS listeners start.
+func startACME() {
+	if !*acmeEnabled {
+		return
+	}
+
+	acmeManager = &autocert.Manager{
+		Prompt:      autocert.AcceptTOS,
+		Email:       *acmeEmail,
+		Client:      &acme.Client{DirectoryURL: *acmeDirectoryURL},
+		HostPolicy:  acmeHostPolicy,
+		Cache:       acmeSecretCache{},
+		RenewBefore: *acmeRenewBefore,
+	}
+
+	log.Print("acme: automatic certificate issuance enabled, directory: ", *acmeDirectoryURL)
+}
+
+// acmeHostPolicy only allows issuance for hosts an Ingress opted into via
+// the acme annotation (see k8s.ACMEHosts).
+func acmeHostPolicy(ctx context.Context, host string) error {
+	if _, ok := k8s.ACMEHosts()[host]; !ok {
+		return fmt.Errorf("acme: host %q is not opted into automatic issuance", host)
+	}
+	return nil
+}
+
+// acmeHTTPHandler wraps fallback with the ACME HTTP-01 challenge responder,
+// when ACME issuance is enabled; otherwise it returns fallback unchanged.
+func acmeHTTPHandler(fallback http.Handler) http.Handler {
+	if acmeManager == nil {
+		return fallback
+	}
+	return acmeManager.HTTPHandler(fallback)
+}
+
+// acmeCertificate returns an automatically-issued certificate for hello's
+// SNI host, performing issuance (and the HTTP-01 challenge) synchronously
+// on first use if none is cached yet. It returns an error if ACME is
+// disabled or the host isn't eligible.
+func acmeCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if acmeManager == nil {
+		return nil, errors.New("acme: not enabled")
+	}
+	return acmeManager.GetCertificate(hello)
+}
+
+// acmeHostEligible reports whether host has opted into automatic issuance.
+func acmeHostEligible(host string) bool {
+	if acmeManager == nil {
+		return false
+	}
+	_, ok := k8s.ACMEHosts()[host]
+	return ok
+}
+
+// acmeStatus summarizes issuance state for every ACME-eligible host, for
+// the /config endpoint and the status template.
+func acmeStatus() map[string]string {
+	status := map[string]string{}
+
+	if acmeManager == nil {
+		return status
+	}
+
+	for host := range k8s.ACMEHosts() {
+		if _, ok := config.Current.HostCerts[host]; ok {
+			status[host] = "issued"
+		} else {
+			status[host] = "pending"
+		}
+	}
+
+	return status
+}
+
+// acmeSecretCache implements autocert.Cache. Certificates (cache keys
+// naming a plain host, e.g. "example.com") are persisted into the
+// Kubernetes Secret referenced by that host's ingress TLS entry, so other
+// replicas pick them up through the existing secret watcher. Everything
+// else (the account key, and short-lived HTTP-01/TLS-ALPN-01 challenge
+// tokens) is kept in an in-process map: losing it on restart only costs a
+// re-registration or a retried challenge, not a certificate.
+type acmeSecretCache struct{}
+
+var (
+	acmeMemCacheMu sync.Mutex
+	acmeMemCache   = map[string][]byte{}
+)
+
+func (acmeSecretCache) Get(ctx context.Context, key string) ([]byte, error) {
+	host, ok := acmeCacheCertHost(key)
+	if !ok {
+		acmeMemCacheMu.Lock()
+		data, ok := acmeMemCache[key]
+		acmeMemCacheMu.Unlock()
+		if !ok {
+			return nil, autocert.ErrCacheMiss
+		}
+		return data, nil
+	}
+
+	ref, ok := k8s.ACMEHosts()[host]
+	if !ok {
+		return nil, autocert.ErrCacheMiss
+	}
+
+	secret, err := kubeclient.Client().CoreV1().Secrets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, autocert.ErrCacheMiss
+	} else if err != nil {
+		return nil, err
+	}
+
+	keyPEM := secret.Data[corev1.TLSPrivateKeyKey]
+	crtPEM := secret.Data[corev1.TLSCertKey]
+	if len(keyPEM) == 0 || len(crtPEM) == 0 {
+		return nil, autocert.ErrCacheMiss
+	}
+
+	// autocert's own on-disk cache format is the key PEM block immediately
+	// followed by the certificate chain PEM blocks; a Kubernetes TLS
+	// Secret's tls.key/tls.crt are each already PEM, so concatenating them
+	// reproduces it exactly.
+	return append(append([]byte{}, keyPEM...), crtPEM...), nil
+}
+
+func (acmeSecretCache) Put(ctx context.Context, key string, data []byte) error {
+	host, ok := acmeCacheCertHost(key)
+	if !ok {
+		acmeMemCacheMu.Lock()
+		acmeMemCache[key] = data
+		acmeMemCacheMu.Unlock()
+		return nil
+	}
+
+	ref, ok := k8s.ACMEHosts()[host]
+	if !ok {
+		return fmt.Errorf("acme: no secret known for host %q", host)
+	}
+
+	keyBlock, rest := pem.Decode(data)
+	if keyBlock == nil {
+		return fmt.Errorf("acme: cache entry for %q has no PEM key block", host)
+	}
+
+	secrets := kubeclient.Client().CoreV1().Secrets(ref.Namespace)
+
+	secret, err := secrets.Get(ctx, ref.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ref.Namespace, Name: ref.Name},
+			Type:       corev1.SecretTypeTLS,
+		}
+		secret.Data = map[string][]byte{
+			corev1.TLSPrivateKeyKey: pem.EncodeToMemory(keyBlock),
+			corev1.TLSCertKey:       bytes.TrimSpace(rest),
+		}
+		_, err = secrets.Create(ctx, secret, metav1.CreateOptions{})
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Type = corev1.SecretTypeTLS
+	secret.Data[corev1.TLSPrivateKeyKey] = pem.EncodeToMemory(keyBlock)
+	secret.Data[corev1.TLSCertKey] = bytes.TrimSpace(rest)
+
+	_, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+	return err
+}
+
+func (acmeSecretCache) Delete(ctx context.Context, key string) error {
+	if _, ok := acmeCacheCertHost(key); ok {
+		// the referenced Secret is left in place: it may still be in use
+		// by other hosts, and a delete here would just cause it to be
+		// re-issued on the next handshake anyway.
+		return nil
+	}
+
+	acmeMemCacheMu.Lock()
+	delete(acmeMemCache, key)
+	acmeMemCacheMu.Unlock()
+
+	return nil
+}
+
+// acmeCacheCertHost reports whether key names a plain certificate cache
+// entry (as opposed to an account key or challenge token, which autocert
+// suffixes with "+something") and, if so, the host it's for.
+func acmeCacheCertHost(key string) (string, bool) {
+	for i := 0; i < len(key); i++ {
+		if key[i] == '+' {
+			return "", false
+		}
+	}
+	return key, key != "" && key != "acme_account"
+}
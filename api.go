@@ -11,6 +11,8 @@ import (
 	"time"
 
 	"github.com/mcluseau/kingress/config"
+	"github.com/mcluseau/kingress/k8s"
+	"github.com/mcluseau/kingress/metrics"
 )
 
 func startAPI(apiBind string) error {
@@ -37,6 +39,9 @@ func (_ apiHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	case "/config":
 		writeConfig(w)
 
+	case "/metrics":
+		metrics.Handler().ServeHTTP(w, r)
+
 	default:
 		http.NotFound(w, r)
 		return
@@ -53,13 +58,21 @@ func writeConfig(w http.ResponseWriter) {
 
 	certs := make(map[string]*certInfo, len(cfg.HostCerts))
 	for host, cert := range cfg.HostCerts {
-		certs[host] = newCertInfo(cert)
+		info := newCertInfo(cert)
+		certs[host] = info
+
+		if info.Defined && info.Error == nil {
+			metrics.SetCertExpiry(host, info.NotAfter)
+		}
 	}
 
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"backends":            cfg.HostBackends,
 		"default-certificate": newCertInfo(cfg.DefaultCert),
 		"certificates":        certs,
+		"endpoint-health":     k8s.HealthStatus(),
+		"access-log-dropped":  accessLogDroppedCount(),
+		"acme":                acmeStatus(),
 	})
 }
 
@@ -160,6 +173,24 @@ var statusTemplate = template.Must(template.New("status").
 </tr>
 {{ end }}{{ end }}
 </tbody></table>
+
+<h2>HTTP/2 backend transport</h2>
+<table class="table">
+<tbody>
+<tr><td>Ping interval</td><td>{{ .HTTP2PingInterval }}</td></tr>
+<tr><td>Strict max concurrent streams</td><td>{{ .HTTP2StrictMaxConcurrentStreams }}</td></tr>
+</tbody></table>
+
+{{ if .ACME }}
+<h2>ACME issuance</h2>
+<table class="table">
+<thead><tr><th>Host</th><th>Status</th></tr></thead>
+<tbody>
+{{ range $host, $status := .ACME }}
+<tr><td>{{ $host }}</td><td>{{ $status }}</td></tr>
+{{ end }}
+</tbody></table>
+{{ end }}
 `))
 
 func writeStatus(w http.ResponseWriter) {
@@ -183,9 +214,12 @@ func writeStatus(w http.ResponseWriter) {
 	}
 
 	err := statusTemplate.Execute(w, map[string]interface{}{
-		"Backends":           cfg.HostBackends,
-		"DefaultCertificate": newCertInfo(cfg.DefaultCert),
-		"Certificates":       certs,
+		"Backends":                        cfg.HostBackends,
+		"DefaultCertificate":              newCertInfo(cfg.DefaultCert),
+		"Certificates":                    certs,
+		"HTTP2PingInterval":               *http2PingInterval,
+		"HTTP2StrictMaxConcurrentStreams": *http2StrictMaxConcurrentStreams,
+		"ACME":                            acmeStatus(),
 	})
 
 	if err != nil {
@@ -0,0 +1,161 @@
+// Package v1 holds a minimal, hand-written subset of the Gateway API
+// (gateway.networking.k8s.io/v1) types kingress needs to resolve
+// GatewayClass/Gateway/HTTPRoute/TLSRoute into backends and certificates,
+// the same way apis/kingress/v1 does for kingress's own CRDs, without
+// vendoring sigs.k8s.io/gateway-api.
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const GroupName = "gateway.networking.k8s.io"
+
+// GatewayClass is implemented by a controller; only Gateways naming a
+// GatewayClass whose Spec.ControllerName matches ours are honored.
+type GatewayClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec GatewayClassSpec `json:"spec"`
+}
+
+type GatewayClassSpec struct {
+	ControllerName string `json:"controllerName"`
+}
+
+type GatewayClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []GatewayClass `json:"items"`
+}
+
+// Gateway binds a set of listeners (host/port/TLS) to a GatewayClass;
+// HTTPRoute/TLSRoute attach to it through a ParentReference.
+type Gateway struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GatewaySpec   `json:"spec"`
+	Status GatewayStatus `json:"status,omitempty"`
+}
+
+type GatewaySpec struct {
+	GatewayClassName string     `json:"gatewayClassName"`
+	Listeners        []Listener `json:"listeners"`
+}
+
+type Listener struct {
+	Name     string            `json:"name"`
+	Hostname *string           `json:"hostname,omitempty"`
+	Port     int32             `json:"port"`
+	Protocol string            `json:"protocol"`
+	TLS      *GatewayTLSConfig `json:"tls,omitempty"`
+}
+
+type GatewayTLSConfig struct {
+	CertificateRefs []SecretObjectReference `json:"certificateRefs,omitempty"`
+}
+
+type SecretObjectReference struct {
+	Name      string  `json:"name"`
+	Namespace *string `json:"namespace,omitempty"`
+}
+
+type GatewayStatus struct {
+	Addresses []GatewayStatusAddress `json:"addresses,omitempty"`
+}
+
+type GatewayStatusAddress struct {
+	Type  string `json:"type,omitempty"`
+	Value string `json:"value"`
+}
+
+type GatewayList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []Gateway `json:"items"`
+}
+
+// ParentReference names the Gateway an HTTPRoute/TLSRoute attaches to.
+type ParentReference struct {
+	Name      string  `json:"name"`
+	Namespace *string `json:"namespace,omitempty"`
+}
+
+// HTTPBackendRef is a weighted target service for a route rule.
+type HTTPBackendRef struct {
+	Name      string  `json:"name"`
+	Namespace *string `json:"namespace,omitempty"`
+	Port      int32   `json:"port,omitempty"`
+
+	// Weight controls this backendRef's share of traffic relative to the
+	// rule's other backendRefs; defaults to 1 when unset.
+	Weight *int32 `json:"weight,omitempty"`
+}
+
+// HTTPRoute routes HTTP traffic arriving at its parent Gateway(s) by
+// hostname and path to one or more weighted backends.
+type HTTPRoute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec HTTPRouteSpec `json:"spec"`
+}
+
+type HTTPRouteSpec struct {
+	ParentRefs []ParentReference `json:"parentRefs,omitempty"`
+	Hostnames  []string          `json:"hostnames,omitempty"`
+	Rules      []HTTPRouteRule   `json:"rules,omitempty"`
+}
+
+type HTTPRouteRule struct {
+	Matches     []HTTPRouteMatch `json:"matches,omitempty"`
+	BackendRefs []HTTPBackendRef `json:"backendRefs,omitempty"`
+}
+
+type HTTPRouteMatch struct {
+	Path *HTTPPathMatch `json:"path,omitempty"`
+}
+
+// HTTPPathMatch.Type is "Exact" or "PathPrefix" (PathPrefix is assumed for
+// any other/empty value, matching the Gateway API's own default).
+type HTTPPathMatch struct {
+	Type  string `json:"type,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+type HTTPRouteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []HTTPRoute `json:"items"`
+}
+
+// TLSRoute routes by SNI instead of HTTP host/path; kingress resolves it
+// into a plain, path-less backend for the SNI hostnames it claims.
+type TLSRoute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec TLSRouteSpec `json:"spec"`
+}
+
+type TLSRouteSpec struct {
+	ParentRefs []ParentReference `json:"parentRefs,omitempty"`
+	Hostnames  []string          `json:"hostnames,omitempty"`
+	Rules      []TLSRouteRule    `json:"rules,omitempty"`
+}
+
+type TLSRouteRule struct {
+	BackendRefs []HTTPBackendRef `json:"backendRefs,omitempty"`
+}
+
+type TLSRouteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []TLSRoute `json:"items"`
+}
@@ -0,0 +1,338 @@
+package v1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func (in *GatewayClassSpec) DeepCopyInto(out *GatewayClassSpec) {
+	*out = *in
+}
+
+func (in *GatewayClass) DeepCopyInto(out *GatewayClass) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+func (in *GatewayClass) DeepCopy() *GatewayClass {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayClass)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *GatewayClass) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+func (in *GatewayClassList) DeepCopyInto(out *GatewayClassList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		out.Items = make([]GatewayClass, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+func (in *GatewayClassList) DeepCopy() *GatewayClassList {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayClassList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *GatewayClassList) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+func (in *SecretObjectReference) DeepCopyInto(out *SecretObjectReference) {
+	*out = *in
+	if in.Namespace != nil {
+		out.Namespace = new(string)
+		*out.Namespace = *in.Namespace
+	}
+}
+
+func (in *GatewayTLSConfig) DeepCopyInto(out *GatewayTLSConfig) {
+	*out = *in
+	if in.CertificateRefs != nil {
+		out.CertificateRefs = make([]SecretObjectReference, len(in.CertificateRefs))
+		for i := range in.CertificateRefs {
+			in.CertificateRefs[i].DeepCopyInto(&out.CertificateRefs[i])
+		}
+	}
+}
+
+func (in *Listener) DeepCopyInto(out *Listener) {
+	*out = *in
+	if in.Hostname != nil {
+		out.Hostname = new(string)
+		*out.Hostname = *in.Hostname
+	}
+	if in.TLS != nil {
+		out.TLS = new(GatewayTLSConfig)
+		in.TLS.DeepCopyInto(out.TLS)
+	}
+}
+
+func (in *GatewaySpec) DeepCopyInto(out *GatewaySpec) {
+	*out = *in
+	if in.Listeners != nil {
+		out.Listeners = make([]Listener, len(in.Listeners))
+		for i := range in.Listeners {
+			in.Listeners[i].DeepCopyInto(&out.Listeners[i])
+		}
+	}
+}
+
+func (in *GatewayStatusAddress) DeepCopyInto(out *GatewayStatusAddress) {
+	*out = *in
+}
+
+func (in *GatewayStatus) DeepCopyInto(out *GatewayStatus) {
+	*out = *in
+	if in.Addresses != nil {
+		out.Addresses = make([]GatewayStatusAddress, len(in.Addresses))
+		copy(out.Addresses, in.Addresses)
+	}
+}
+
+func (in *Gateway) DeepCopyInto(out *Gateway) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+func (in *Gateway) DeepCopy() *Gateway {
+	if in == nil {
+		return nil
+	}
+	out := new(Gateway)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *Gateway) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+func (in *GatewayList) DeepCopyInto(out *GatewayList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		out.Items = make([]Gateway, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+func (in *GatewayList) DeepCopy() *GatewayList {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *GatewayList) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+func (in *ParentReference) DeepCopyInto(out *ParentReference) {
+	*out = *in
+	if in.Namespace != nil {
+		out.Namespace = new(string)
+		*out.Namespace = *in.Namespace
+	}
+}
+
+func (in *HTTPBackendRef) DeepCopyInto(out *HTTPBackendRef) {
+	*out = *in
+	if in.Namespace != nil {
+		out.Namespace = new(string)
+		*out.Namespace = *in.Namespace
+	}
+	if in.Weight != nil {
+		out.Weight = new(int32)
+		*out.Weight = *in.Weight
+	}
+}
+
+func (in *HTTPPathMatch) DeepCopyInto(out *HTTPPathMatch) {
+	*out = *in
+}
+
+func (in *HTTPRouteMatch) DeepCopyInto(out *HTTPRouteMatch) {
+	*out = *in
+	if in.Path != nil {
+		out.Path = new(HTTPPathMatch)
+		in.Path.DeepCopyInto(out.Path)
+	}
+}
+
+func (in *HTTPRouteRule) DeepCopyInto(out *HTTPRouteRule) {
+	*out = *in
+	if in.Matches != nil {
+		out.Matches = make([]HTTPRouteMatch, len(in.Matches))
+		for i := range in.Matches {
+			in.Matches[i].DeepCopyInto(&out.Matches[i])
+		}
+	}
+	if in.BackendRefs != nil {
+		out.BackendRefs = make([]HTTPBackendRef, len(in.BackendRefs))
+		for i := range in.BackendRefs {
+			in.BackendRefs[i].DeepCopyInto(&out.BackendRefs[i])
+		}
+	}
+}
+
+func (in *HTTPRouteSpec) DeepCopyInto(out *HTTPRouteSpec) {
+	*out = *in
+	if in.ParentRefs != nil {
+		out.ParentRefs = make([]ParentReference, len(in.ParentRefs))
+		for i := range in.ParentRefs {
+			in.ParentRefs[i].DeepCopyInto(&out.ParentRefs[i])
+		}
+	}
+	if in.Hostnames != nil {
+		out.Hostnames = append([]string{}, in.Hostnames...)
+	}
+	if in.Rules != nil {
+		out.Rules = make([]HTTPRouteRule, len(in.Rules))
+		for i := range in.Rules {
+			in.Rules[i].DeepCopyInto(&out.Rules[i])
+		}
+	}
+}
+
+func (in *HTTPRoute) DeepCopyInto(out *HTTPRoute) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+func (in *HTTPRoute) DeepCopy() *HTTPRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *HTTPRoute) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+func (in *HTTPRouteList) DeepCopyInto(out *HTTPRouteList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		out.Items = make([]HTTPRoute, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+func (in *HTTPRouteList) DeepCopy() *HTTPRouteList {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPRouteList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *HTTPRouteList) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+func (in *TLSRouteRule) DeepCopyInto(out *TLSRouteRule) {
+	*out = *in
+	if in.BackendRefs != nil {
+		out.BackendRefs = make([]HTTPBackendRef, len(in.BackendRefs))
+		for i := range in.BackendRefs {
+			in.BackendRefs[i].DeepCopyInto(&out.BackendRefs[i])
+		}
+	}
+}
+
+func (in *TLSRouteSpec) DeepCopyInto(out *TLSRouteSpec) {
+	*out = *in
+	if in.ParentRefs != nil {
+		out.ParentRefs = make([]ParentReference, len(in.ParentRefs))
+		for i := range in.ParentRefs {
+			in.ParentRefs[i].DeepCopyInto(&out.ParentRefs[i])
+		}
+	}
+	if in.Hostnames != nil {
+		out.Hostnames = append([]string{}, in.Hostnames...)
+	}
+	if in.Rules != nil {
+		out.Rules = make([]TLSRouteRule, len(in.Rules))
+		for i := range in.Rules {
+			in.Rules[i].DeepCopyInto(&out.Rules[i])
+		}
+	}
+}
+
+func (in *TLSRoute) DeepCopyInto(out *TLSRoute) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+func (in *TLSRoute) DeepCopy() *TLSRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *TLSRoute) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+func (in *TLSRouteList) DeepCopyInto(out *TLSRouteList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		out.Items = make([]TLSRoute, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+func (in *TLSRouteList) DeepCopy() *TLSRouteList {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSRouteList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *TLSRouteList) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
@@ -0,0 +1,136 @@
+// Package v1 holds the types for kingress's own CRDs
+// (kingress.mcluseau.github.io/v1), used for routing that the stock
+// networking.k8s.io Ingress resource can't express: host regexes,
+// method/header predicates, path rewrites and middleware chains.
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const GroupName = "kingress.mcluseau.github.io"
+
+// IngressRoute is a collection of routing rules that produce backends the
+// same way an Ingress does but with richer predicates and actions. Routes
+// sharing a Host are tried longest PathPrefix first, like Ingress paths; the
+// order among such routes with equally long prefixes is not defined.
+// IngressRoute-derived backends win over Ingress-derived ones for the same
+// host/path prefix.
+type IngressRoute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec IngressRouteSpec `json:"spec"`
+}
+
+type IngressRouteSpec struct {
+	Routes []Route `json:"routes"`
+}
+
+// Route matches requests against its predicates (all given ones must match)
+// and, for matches, routes to Service/Port after applying Actions.
+type Route struct {
+	// Host matches the request's Host header, supporting a leading "*." for
+	// a wildcard subdomain.
+	Host string `json:"host,omitempty"`
+
+	// HostRegexp matches the request's Host header against a regexp.
+	HostRegexp string `json:"hostRegexp,omitempty"`
+
+	// PathPrefix matches requests whose path starts with it.
+	PathPrefix string `json:"pathPrefix,omitempty"`
+
+	// PathRegexp matches the request path against a regexp.
+	PathRegexp string `json:"pathRegexp,omitempty"`
+
+	// Method, when set, restricts the match to this HTTP method.
+	Method string `json:"method,omitempty"`
+
+	// Headers, when set, requires each named header to match its regexp.
+	Headers []HeaderMatch `json:"headers,omitempty"`
+
+	Service string `json:"service"`
+	Port    string `json:"port"`
+
+	Actions     RouteActions `json:"actions,omitempty"`
+	Middlewares []string     `json:"middlewares,omitempty"`
+}
+
+type HeaderMatch struct {
+	Name   string `json:"name"`
+	Regexp string `json:"regexp"`
+}
+
+type RouteActions struct {
+	// StripPrefix removes this prefix from the path before forwarding.
+	StripPrefix string `json:"stripPrefix,omitempty"`
+
+	// AddPrefix prepends this prefix to the path before forwarding.
+	AddPrefix string `json:"addPrefix,omitempty"`
+
+	// ReplacePathRegex rewrites the path by replacing matches of Regexp
+	// with Replacement (in regexp.ReplaceAll syntax).
+	ReplacePathRegex *PathRegexReplace `json:"replacePathRegex,omitempty"`
+}
+
+type PathRegexReplace struct {
+	Regexp      string `json:"regexp"`
+	Replacement string `json:"replacement"`
+}
+
+type IngressRouteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []IngressRoute `json:"items"`
+}
+
+// Middleware is a named, reusable piece of request handling (basic auth, IP
+// allow-listing) that a Route can reference by name; its rate limiting and
+// CORS kinds are not applied (see RateLimitMiddleware).
+type Middleware struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec MiddlewareSpec `json:"spec"`
+}
+
+type MiddlewareSpec struct {
+	BasicAuth   *BasicAuthMiddleware   `json:"basicAuth,omitempty"`
+	IPAllowList *IPAllowListMiddleware `json:"ipAllowList,omitempty"`
+	RateLimit   *RateLimitMiddleware   `json:"rateLimit,omitempty"`
+	CORS        *CORSMiddleware        `json:"cors,omitempty"`
+}
+
+type BasicAuthMiddleware struct {
+	// SecretRef names a namespace-local Opaque Secret whose "users" key holds
+	// the allowed credentials, one plain "user:password" pair per line.
+	// Secrets of type kubernetes.io/basic-auth and hashed (htpasswd)
+	// passwords are not recognized.
+	SecretRef string `json:"secretRef"`
+	Realm     string `json:"realm,omitempty"`
+}
+
+type IPAllowListMiddleware struct {
+	CIDRs []string `json:"cidrs"`
+}
+
+// RateLimitMiddleware and CORSMiddleware describe the shape used by the
+// dedicated rate-limiting and CORS annotations. A Middleware's rateLimit and
+// cors fields are accepted but not applied: a Route referencing a Middleware
+// only gets its basicAuth and ipAllowList behaviour.
+type RateLimitMiddleware struct {
+	RequestsPerSecond int `json:"requestsPerSecond,omitempty"`
+	Burst             int `json:"burst,omitempty"`
+}
+
+type CORSMiddleware struct {
+	AllowedOrigins []string `json:"allowedOrigins,omitempty"`
+}
+
+type MiddlewareList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []Middleware `json:"items"`
+}
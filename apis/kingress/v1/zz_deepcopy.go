@@ -0,0 +1,176 @@
+package v1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func (in *HeaderMatch) DeepCopy() *HeaderMatch {
+	out := *in
+	return &out
+}
+
+func (in *PathRegexReplace) DeepCopy() *PathRegexReplace {
+	out := *in
+	return &out
+}
+
+func (in *RouteActions) DeepCopyInto(out *RouteActions) {
+	*out = *in
+	if in.ReplacePathRegex != nil {
+		out.ReplacePathRegex = in.ReplacePathRegex.DeepCopy()
+	}
+}
+
+func (in *Route) DeepCopyInto(out *Route) {
+	*out = *in
+	if in.Headers != nil {
+		out.Headers = make([]HeaderMatch, len(in.Headers))
+		copy(out.Headers, in.Headers)
+	}
+	if in.Middlewares != nil {
+		out.Middlewares = append([]string{}, in.Middlewares...)
+	}
+	in.Actions.DeepCopyInto(&out.Actions)
+}
+
+func (in *IngressRouteSpec) DeepCopyInto(out *IngressRouteSpec) {
+	*out = *in
+	if in.Routes != nil {
+		out.Routes = make([]Route, len(in.Routes))
+		for i := range in.Routes {
+			in.Routes[i].DeepCopyInto(&out.Routes[i])
+		}
+	}
+}
+
+func (in *IngressRoute) DeepCopyInto(out *IngressRoute) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+func (in *IngressRoute) DeepCopy() *IngressRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *IngressRoute) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+func (in *IngressRouteList) DeepCopyInto(out *IngressRouteList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		out.Items = make([]IngressRoute, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+func (in *IngressRouteList) DeepCopy() *IngressRouteList {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressRouteList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *IngressRouteList) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+func (in *BasicAuthMiddleware) DeepCopy() *BasicAuthMiddleware {
+	out := *in
+	return &out
+}
+
+func (in *IPAllowListMiddleware) DeepCopy() *IPAllowListMiddleware {
+	out := *in
+	if in.CIDRs != nil {
+		out.CIDRs = append([]string{}, in.CIDRs...)
+	}
+	return &out
+}
+
+func (in *RateLimitMiddleware) DeepCopy() *RateLimitMiddleware {
+	out := *in
+	return &out
+}
+
+func (in *CORSMiddleware) DeepCopy() *CORSMiddleware {
+	out := *in
+	if in.AllowedOrigins != nil {
+		out.AllowedOrigins = append([]string{}, in.AllowedOrigins...)
+	}
+	return &out
+}
+
+func (in *MiddlewareSpec) DeepCopyInto(out *MiddlewareSpec) {
+	*out = *in
+	if in.BasicAuth != nil {
+		out.BasicAuth = in.BasicAuth.DeepCopy()
+	}
+	if in.IPAllowList != nil {
+		out.IPAllowList = in.IPAllowList.DeepCopy()
+	}
+	if in.RateLimit != nil {
+		out.RateLimit = in.RateLimit.DeepCopy()
+	}
+	if in.CORS != nil {
+		out.CORS = in.CORS.DeepCopy()
+	}
+}
+
+func (in *Middleware) DeepCopyInto(out *Middleware) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+func (in *Middleware) DeepCopy() *Middleware {
+	if in == nil {
+		return nil
+	}
+	out := new(Middleware)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *Middleware) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+func (in *MiddlewareList) DeepCopyInto(out *MiddlewareList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		out.Items = make([]Middleware, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+func (in *MiddlewareList) DeepCopy() *MiddlewareList {
+	if in == nil {
+		return nil
+	}
+	out := new(MiddlewareList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *MiddlewareList) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
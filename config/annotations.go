@@ -1,9 +1,12 @@
 package config
 
 import (
+	"fmt"
 	"net"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -57,6 +60,229 @@ var Annotations = []Annotation{
 			return
 		},
 	},
+	{
+		Name:        "enable-cors",
+		Description: fromNginx + "#enable-cors). Unlike cors-allowed-origins, this turns on CORS headers even with no allow-list configured (i.e. any origin is reflected back).",
+		get:         func(o *BackendOptions) any { return o.CORSEnabled },
+		apply: func(o *BackendOptions, value string) error {
+			o.CORSEnabled = boolOpt(value)
+			return nil
+		},
+	},
+	{
+		Name:        "cors-allow-methods",
+		Description: fromNginx + "#enable-cors). Comma-separated list of methods sent in Access-Control-Allow-Methods. Defaults to GET, PUT, POST, DELETE, PATCH, OPTIONS.",
+		get:         func(o *BackendOptions) any { return o.CORSAllowMethods },
+		apply: func(o *BackendOptions, value string) (err error) {
+			o.CORSAllowMethods, err = stringListOpt(value)
+			return
+		},
+	},
+	{
+		Name:        "cors-allow-headers",
+		Description: fromNginx + "#enable-cors). Comma-separated list of headers sent in Access-Control-Allow-Headers. Defaults to reflecting the preflight's Access-Control-Request-Headers.",
+		get:         func(o *BackendOptions) any { return o.CORSAllowHeaders },
+		apply: func(o *BackendOptions, value string) (err error) {
+			o.CORSAllowHeaders, err = stringListOpt(value)
+			return
+		},
+	},
+	{
+		Name:        "cors-expose-headers",
+		Description: fromNginx + "#enable-cors). Comma-separated list of headers sent in Access-Control-Expose-Headers.",
+		get:         func(o *BackendOptions) any { return o.CORSExposeHeaders },
+		apply: func(o *BackendOptions, value string) (err error) {
+			o.CORSExposeHeaders, err = stringListOpt(value)
+			return
+		},
+	},
+	{
+		Name:        "cors-allow-credentials",
+		Description: fromNginx + "#enable-cors). Sends Access-Control-Allow-Credentials: true. Off by default.",
+		get:         func(o *BackendOptions) any { return o.CORSAllowCredentials },
+		apply: func(o *BackendOptions, value string) error {
+			o.CORSAllowCredentials = boolOpt(value)
+			return nil
+		},
+	},
+	{
+		Name:        "cors-max-age",
+		Description: fromNginx + "#enable-cors). How long (in seconds) a preflight's result may be cached by the client. Unset omits Access-Control-Max-Age entirely.",
+		get:         func(o *BackendOptions) any { return int(o.CORSMaxAge / time.Second) },
+		apply: func(o *BackendOptions, value string) error {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				o.CORSMaxAge = 0
+				return nil
+			}
+			seconds, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("bad cors-max-age %q: %w", value, err)
+			}
+			o.CORSMaxAge = time.Duration(seconds) * time.Second
+			return nil
+		},
+	},
+	{
+		Name:        "tls-profile",
+		Description: "TLS profile to use for this ingress' HTTPS listener: secure, default or legacy. Defaults to the -tls-profile flag's value.",
+		get:         func(o *BackendOptions) any { return o.TLSProfile },
+		apply: func(o *BackendOptions, value string) (err error) {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				o.TLSProfile = ""
+				return
+			}
+			o.TLSProfile, err = ParseTLSProfile(value)
+			return
+		},
+	},
+	{
+		Name:        "ssl-min-protocol-version",
+		Description: "Minimum TLS version for this ingress (TLSv1.2 or TLSv1.3; TLSv1.0 and TLSv1.1 are rejected), overriding tls-profile's own floor on both the client-facing listener and secure-backends connections.",
+		get:         func(o *BackendOptions) any { return o.SSLMinProtocolVersion },
+		apply: func(o *BackendOptions, value string) error {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				o.SSLMinProtocolVersion = 0
+				return nil
+			}
+			v, err := ParseMinTLSVersion(value)
+			if err != nil {
+				return err
+			}
+			o.SSLMinProtocolVersion = v
+			return nil
+		},
+	},
+	{
+		Name:        "ssl-ciphers",
+		Description: "Comma-separated list of Go cipher suite names allowed for this ingress, overriding tls-profile's own list; ignored once the effective minimum version is TLSv1.3.",
+		get:         func(o *BackendOptions) any { return o.SSLCipherSuites },
+		apply: func(o *BackendOptions, value string) (err error) {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				o.SSLCipherSuites = nil
+				return nil
+			}
+			o.SSLCipherSuites, err = ParseCipherSuites(value)
+			return
+		},
+	},
+	{
+		Name:        "http2-cleartext",
+		Description: "Speak h2c (HTTP/2 without TLS) to this backend instead of HTTP/1.1, for plain-HTTP/2 and gRPC backends that don't terminate TLS themselves.",
+		get:         func(o *BackendOptions) any { return o.HTTP2Cleartext },
+		apply: func(o *BackendOptions, value string) error {
+			o.HTTP2Cleartext = boolOpt(value)
+			return nil
+		},
+	},
+	{
+		Name:        "grpc-backend",
+		Description: "Mark this backend as gRPC: forward over h2c (or h2, combined with secure-backends) instead of HTTP/1.1, preserving trailers and grpc-timeout. Mirrors the ingress-nginx backend-protocol: GRPC annotation.",
+		get:         func(o *BackendOptions) any { return o.GRPCBackend },
+		apply: func(o *BackendOptions, value string) error {
+			o.GRPCBackend = boolOpt(value)
+			return nil
+		},
+	},
+	{
+		Name:        "lb-policy",
+		Description: "Load-balancing policy across endpoint targets: random (default), round-robin, weighted, least-connections or consistent-hash.",
+		get:         func(o *BackendOptions) any { return o.LBPolicy },
+		apply: func(o *BackendOptions, value string) error {
+			value = strings.TrimSpace(value)
+			switch value {
+			case "", PolicyRandom, PolicyRoundRobin, PolicyWeighted, PolicyLeastConnections, PolicyConsistentHash:
+				o.LBPolicy = value
+				return nil
+			default:
+				return fmt.Errorf("unknown lb-policy %q", value)
+			}
+		},
+	},
+	{
+		Name:        "lb-hash-header",
+		Description: "Header used as the consistent-hash affinity key. Defaults to the client's source IP when unset.",
+		get:         func(o *BackendOptions) any { return o.LBHashHeader },
+		apply: func(o *BackendOptions, value string) error {
+			o.LBHashHeader = strings.TrimSpace(value)
+			return nil
+		},
+	},
+	{
+		Name:        "health-check-path",
+		Description: "HTTP path actively probed on each endpoint target. When unset, targets are probed with a plain TCP dial.",
+		get:         func(o *BackendOptions) any { return o.HealthCheckPath },
+		apply: func(o *BackendOptions, value string) error {
+			o.HealthCheckPath = strings.TrimSpace(value)
+			return nil
+		},
+	},
+	{
+		Name:        "health-check-interval",
+		Description: "Delay between active health check probes of each endpoint target (Go duration syntax, e.g. \"10s\"). Defaults to 10s.",
+		get:         func(o *BackendOptions) any { return o.HealthCheckInterval },
+		apply: func(o *BackendOptions, value string) (err error) {
+			o.HealthCheckInterval, err = parseDurationOpt(value)
+			return
+		},
+	},
+	{
+		Name:        "health-check-timeout",
+		Description: "Timeout for a single active health check probe (Go duration syntax, e.g. \"2s\"). Defaults to 2s.",
+		get:         func(o *BackendOptions) any { return o.HealthCheckTimeout },
+		apply: func(o *BackendOptions, value string) (err error) {
+			o.HealthCheckTimeout, err = parseDurationOpt(value)
+			return
+		},
+	},
+	{
+		Name:        "limit-rps",
+		Description: fromNginx + "#rate-limiting). Requests per second allowed from a single client IP to this backend; 0 (default) means unlimited.",
+		get:         func(o *BackendOptions) any { return o.LimitRPS },
+		apply: func(o *BackendOptions, value string) (err error) {
+			o.LimitRPS, err = parseRateOpt(value)
+			return
+		},
+	},
+	{
+		Name:        "limit-rpm",
+		Description: fromNginx + "#rate-limiting). Requests per minute allowed from a single client IP to this backend; 0 (default) means unlimited. Enforced independently from limit-rps.",
+		get:         func(o *BackendOptions) any { return o.LimitRPM },
+		apply: func(o *BackendOptions, value string) (err error) {
+			o.LimitRPM, err = parseRateOpt(value)
+			return
+		},
+	},
+	{
+		Name:        "limit-connections",
+		Description: fromNginx + "#rate-limiting). Maximum number of requests to this backend in flight at once, across all clients; 0 (default) means unlimited.",
+		get:         func(o *BackendOptions) any { return o.LimitConnections },
+		apply: func(o *BackendOptions, value string) error {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				o.LimitConnections = 0
+				return nil
+			}
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("bad limit-connections %q: %w", value, err)
+			}
+			o.LimitConnections = n
+			return nil
+		},
+	},
+	{
+		Name:        "access-log-sample",
+		Description: "Keep only 1 in N access log records for this host, given as \"N\" or \"1/N\". Unset or 1 logs every request.",
+		get:         func(o *BackendOptions) any { return o.AccessLogSample },
+		apply: func(o *BackendOptions, value string) (err error) {
+			o.AccessLogSample, err = parseSampleRate(value)
+			return
+		},
+	},
 }
 
 type Annotation struct {
@@ -80,6 +306,65 @@ func boolOpt(value string) bool {
 	return value == "true"
 }
 
+func parseDurationOpt(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// stringListOpt parses a comma-separated list, trimming whitespace around
+// each entry; an empty value yields a nil (unset) list.
+func stringListOpt(value string) ([]string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	values := strings.Split(value, ",")
+	for i, v := range values {
+		values[i] = strings.TrimSpace(v)
+	}
+
+	return values, nil
+}
+
+// parseRateOpt parses a limit-rps/limit-rpm value, a non-negative number of
+// requests (fractions allowed, e.g. "0.5").
+func parseRateOpt(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("bad rate limit %q: %w", value, err)
+	}
+
+	return rate, nil
+}
+
+// parseSampleRate parses an access-log-sample value given as "N" or "1/N".
+func parseSampleRate(value string) (uint32, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	if _, n, found := strings.Cut(value, "/"); found {
+		value = n
+	}
+
+	rate, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("bad access-log-sample %q: %w", value, err)
+	}
+
+	return uint32(rate), nil
+}
+
 func ipNetArray(value string) ([]*net.IPNet, error) {
 	if value == "" {
 		return nil, nil
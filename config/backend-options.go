@@ -3,6 +3,7 @@ package config
 import (
 	"net"
 	"sort"
+	"time"
 )
 
 type BackendOptions struct {
@@ -10,6 +11,70 @@ type BackendOptions struct {
 	SecureBackends       bool
 	WhitelistSourceRange []*net.IPNet
 	CORSAllowedOrigins   []string
+	TLSProfile           TLSProfile
+
+	// CORSEnabled turns on the CORS response headers below even for
+	// requests carrying an Origin that's not restricted by
+	// CORSAllowedOrigins (i.e. CORS is enabled for all origins).
+	CORSEnabled bool
+
+	CORSAllowMethods     []string
+	CORSAllowHeaders     []string
+	CORSExposeHeaders    []string
+	CORSAllowCredentials bool
+
+	// CORSMaxAge is how long a preflight's result may be cached by the
+	// client; 0 omits Access-Control-Max-Age entirely.
+	CORSMaxAge time.Duration
+
+	// SSLMinProtocolVersion and SSLCipherSuites override TLSProfile's
+	// MinVersion/CipherSuites for this ingress, on both the client-facing
+	// listener and (when SecureBackends is set) the upstream connection.
+	// Zero/empty means "use the profile's own setting". SSLCipherSuites is
+	// ignored (with a warning) once the effective MinVersion is TLS 1.3,
+	// which doesn't support configuring the cipher suite.
+	SSLMinProtocolVersion uint16
+	SSLCipherSuites       []uint16
+
+	// HTTP2Cleartext makes the proxy speak h2c (HTTP/2 without TLS) to this
+	// backend instead of HTTP/1.1, for plain-HTTP/2 and gRPC backends that
+	// don't terminate TLS themselves.
+	HTTP2Cleartext bool
+
+	// GRPCBackend marks this backend as a gRPC service: the proxy forwards
+	// over h2c (or h2, combined with SecureBackends), so gRPC's required
+	// HTTP/2 framing, trailers (grpc-status/grpc-message) and grpc-timeout
+	// propagate untouched.
+	GRPCBackend bool
+
+	// LBPolicy names the BackendPolicy to use (see PolicyRandom and co);
+	// empty means PolicyRandom.
+	LBPolicy string
+
+	// LBHashHeader is the header consistent-hash affinity is keyed on; when
+	// empty, the client's source IP is used instead.
+	LBHashHeader string
+
+	// HealthCheckPath, when set, makes the active health checker probe this
+	// HTTP path instead of just opening a TCP connection to the target.
+	HealthCheckPath     string
+	HealthCheckInterval time.Duration
+	HealthCheckTimeout  time.Duration
+
+	// AccessLogSample, when greater than 1, makes the access logger keep
+	// only 1 in AccessLogSample requests to this backend's host. 0 and 1
+	// both mean "log every request".
+	AccessLogSample uint32
+
+	// LimitRPS and LimitRPM cap the sustained request rate of a single
+	// client (see rateLimitClientIP), enforced by an independent
+	// token-bucket each; 0 means unlimited.
+	LimitRPS float64
+	LimitRPM float64
+
+	// LimitConnections caps the number of requests to this backend in
+	// flight at once, across all clients; 0 means unlimited.
+	LimitConnections int
 }
 
 func (o *BackendOptions) Set(key, value string) (bool, error) {
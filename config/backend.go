@@ -1,8 +1,8 @@
 package config
 
 import (
-	"math/rand"
 	"strings"
+	"sync"
 )
 
 type Backend struct {
@@ -11,6 +11,13 @@ type Backend struct {
 	Targets    []string
 
 	Options BackendOptions
+
+	// Match holds the extra predicates and rewrite actions of an
+	// IngressRoute-derived backend; nil for plain Ingress-derived ones.
+	Match *RouteMatch
+
+	policyOnce sync.Once
+	policy     BackendPolicy
 }
 
 func NewBackend(ingressRef, prefix string, targets ...string) *Backend {
@@ -25,12 +32,27 @@ func (b *Backend) HandlesPath(path string) bool {
 	return strings.HasPrefix(path, b.Prefix)
 }
 
+// Policy returns (building and caching on first use) the load-balancing
+// policy configured for this backend through the lb-policy annotation.
+func (b *Backend) Policy() BackendPolicy {
+	b.policyOnce.Do(func() {
+		b.policy = NewBackendPolicy(b.Options.LBPolicy, b.Targets)
+	})
+	return b.policy
+}
+
+// Target picks a target using the backend's policy, with no affinity key.
+// Use TargetFor when request affinity (consistent-hash) matters.
 func (b *Backend) Target() string {
+	return b.TargetFor("")
+}
+
+// TargetFor picks a target using the backend's policy, keyed by `key` (a
+// header value or client IP) for policies that support affinity.
+func (b *Backend) TargetFor(key string) string {
 	if len(b.Targets) == 0 {
 		return ""
 	}
 
-	target := b.Targets[rand.Intn(len(b.Targets))]
-
-	return target
+	return b.Policy().Pick(key)
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -14,17 +15,61 @@ var (
 
 	changeNum        uint64 = 0
 	appliedChangeNum uint64 = 0
+
+	providersMu   sync.Mutex
+	providerOrder []string
+	providerBuild = map[string]func() Snapshot{}
+
+	afterApplyMu sync.Mutex
+	afterApply   []func()
 )
 
-type NewConfigFunc func() Config
+// OnApplied registers a callback run every time a new Config is applied
+// (see NotifyChanged), after Current has been swapped in and the lock
+// released. Subsystems that keep their own per-backend state (e.g. the
+// rate limiter) use this to reconcile it against the latest set of
+// backends instead of rebuilding from scratch.
+func OnApplied(f func()) {
+	afterApplyMu.Lock()
+	defer afterApplyMu.Unlock()
+
+	afterApply = append(afterApply, f)
+}
+
+func runAfterApply() {
+	afterApplyMu.Lock()
+	callbacks := append([]func(){}, afterApply...)
+	afterApplyMu.Unlock()
+
+	for _, f := range callbacks {
+		f()
+	}
+}
+
+// RegisterProvider adds provider to the deterministic merge order (first
+// registered, highest priority for a conflicting host/path definition) and
+// records the function used to build its latest Snapshot. Call it once,
+// before the provider starts watching for changes.
+func RegisterProvider(name string, build func() Snapshot) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+
+	if _, known := providerBuild[name]; !known {
+		providerOrder = append(providerOrder, name)
+	}
+	providerBuild[name] = build
+}
 
-func NotifyChanged(callback NewConfigFunc) {
+// NotifyChanged signals that some registered provider's Snapshot changed.
+// The merge and apply is debounced by changeApplyDelay, so a burst of
+// changes (e.g. an initial Kubernetes sync) only rebuilds Config once.
+func NotifyChanged() {
 	changeNum += 1
-	go applyChange(changeNum, callback)
+	go applyChange(changeNum)
 }
 
 // Wait a bit and apply the changes
-func applyChange(myChangeNum uint64, callback NewConfigFunc) {
+func applyChange(myChangeNum uint64) {
 	time.Sleep(*changeApplyDelay)
 
 	if appliedChangeNum >= myChangeNum {
@@ -32,20 +77,20 @@ func applyChange(myChangeNum uint64, callback NewConfigFunc) {
 	}
 
 	Lock()
-	defer Unlock()
 
 	if appliedChangeNum >= myChangeNum {
+		Unlock()
 		return // already applied
 	}
 
-	config := callback()
+	config := mergeSnapshots()
 
 	if len(*customBackends) != 0 {
 		for idx, be := range strings.Split(*customBackends, ",") {
 			parts := strings.Split(be, ":")
 
 			if len(parts) != 3 {
-				log.Fatal("bad custom backend format: %s", be)
+				log.Fatalf("bad custom backend format: %s", be)
 			}
 
 			hostParts := strings.SplitN(parts[0], "/", 2)
@@ -66,4 +111,65 @@ func applyChange(myChangeNum uint64, callback NewConfigFunc) {
 	Current = &config
 
 	appliedChangeNum = changeNum
+
+	Unlock()
+
+	runAfterApply()
+}
+
+// mergeSnapshots builds every registered provider's latest Snapshot and
+// combines them in registration order: a host/path already defined by an
+// earlier provider shadows a later provider's definition for the same
+// host/path.
+func mergeSnapshots() Config {
+	providersMu.Lock()
+	order := append([]string(nil), providerOrder...)
+	builders := make(map[string]func() Snapshot, len(providerBuild))
+	for name, build := range providerBuild {
+		builders[name] = build
+	}
+	providersMu.Unlock()
+
+	merged := Config{HostBackends: Backends{}, HostCerts: Certificates{}}
+
+	for _, name := range order {
+		build := builders[name]
+		if build == nil {
+			continue
+		}
+
+		snap := build()
+
+		for host, backends := range snap.HostBackends {
+		backendsLoop:
+			for _, backend := range backends {
+				for _, existing := range merged.HostBackends[host] {
+					if existing.Prefix == backend.Prefix {
+						merged.Errors = append(merged.Errors, fmt.Sprintf(
+							"warning: duplicate definition for host %s, path %v: provider %s shadowed by an earlier one",
+							host, backend.Prefix, name))
+						continue backendsLoop
+					}
+				}
+
+				merged.HostBackends[host] = append(merged.HostBackends[host], backend)
+			}
+		}
+
+		merged.RouteBackends = append(merged.RouteBackends, snap.RouteBackends...)
+
+		for host, cert := range snap.HostCerts {
+			if _, ok := merged.HostCerts[host]; !ok {
+				merged.HostCerts[host] = cert
+			}
+		}
+
+		if merged.DefaultCert == nil {
+			merged.DefaultCert = snap.DefaultCert
+		}
+
+		merged.Errors = append(merged.Errors, snap.Errors...)
+	}
+
+	return merged
 }
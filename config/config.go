@@ -14,6 +14,12 @@ type Config struct {
 	HostBackends Backends
 	HostCerts    Certificates
 	DefaultCert  *tls.Certificate
+
+	// RouteBackends holds IngressRoute-derived backends matched by a
+	// HostRegexp instead of an exact/wildcard host, so they can't live in
+	// HostBackends' host-keyed map. They're tried, in order, for requests
+	// HostBackends didn't resolve.
+	RouteBackends []*Backend
 }
 
 var (
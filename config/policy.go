@@ -0,0 +1,397 @@
+package config
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// PolicyRandom picks a random target for every request (the historical
+	// behaviour of Backend.Target).
+	PolicyRandom = "random"
+
+	// PolicyRoundRobin cycles through targets in order.
+	PolicyRoundRobin = "round-robin"
+
+	// PolicyWeighted cycles through targets in proportion to their weight
+	// using a smooth weighted round-robin (as used by nginx upstreams), so
+	// traffic stays evenly spread over time instead of bursting onto the
+	// heaviest target. The weight of a target is how many times its address
+	// repeats in the backend's target list, the same "repeat the target"
+	// convention used for Gateway API backendRef weights (see
+	// resolveWeightedTargets).
+	PolicyWeighted = "weighted"
+
+	// PolicyLeastConnections sends the request to the target with the
+	// fewest requests currently in flight.
+	PolicyLeastConnections = "least-connections"
+
+	// PolicyConsistentHash routes requests sharing the same key (a header
+	// value or the source IP, see BackendOptions.LBHashHeader) to the same
+	// target, as long as it stays healthy, using a Maglev-style lookup
+	// table so that losing or adding a target only reshuffles a small
+	// fraction of keys.
+	PolicyConsistentHash = "consistent-hash"
+)
+
+// BackendPolicy selects a target among a Backend's live endpoints.
+//
+// Pick is given an affinity key (e.g. a header value or the client's IP);
+// policies that don't need one (random, round-robin, least-connections)
+// ignore it. MarkSuccess/MarkFailure report the outcome of a request sent to
+// a target, letting the policy evict misbehaving targets and, for
+// least-connections, release the slot reserved by Pick.
+type BackendPolicy interface {
+	Pick(key string) string
+	MarkFailure(target string)
+	MarkSuccess(target string)
+}
+
+// NewBackendPolicy builds the policy named by `name` over the given targets.
+// Unknown or empty names fall back to PolicyRandom.
+func NewBackendPolicy(name string, targets []string) BackendPolicy {
+	switch name {
+	case PolicyRoundRobin:
+		return &roundRobinPolicy{targets: targets}
+	case PolicyWeighted:
+		return newWeightedRoundRobinPolicy(targets)
+	case PolicyLeastConnections:
+		return newLeastConnectionsPolicy(targets)
+	case PolicyConsistentHash:
+		return newConsistentHashPolicy(targets)
+	default:
+		return &randomPolicy{targets: targets}
+	}
+}
+
+// failureTracker evicts a target after consecutive failures, for
+// failureEvictionPeriod or until a success is reported for it, whichever
+// comes first, shared by the policies below.
+type failureTracker struct {
+	mu           sync.Mutex
+	failureCount map[string]int
+	downUntil    map[string]time.Time
+}
+
+const (
+	maxConsecutiveFailures = 3
+	failureEvictionPeriod  = 30 * time.Second
+)
+
+func (t *failureTracker) down(target string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.failureCount[target] < maxConsecutiveFailures {
+		return false
+	}
+
+	if until, ok := t.downUntil[target]; ok && !time.Now().Before(until) {
+		// eviction window elapsed; give the target another chance
+		delete(t.failureCount, target)
+		delete(t.downUntil, target)
+		return false
+	}
+
+	return true
+}
+
+func (t *failureTracker) markFailure(target string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.failureCount == nil {
+		t.failureCount = map[string]int{}
+		t.downUntil = map[string]time.Time{}
+	}
+
+	t.failureCount[target]++
+
+	if t.failureCount[target] >= maxConsecutiveFailures {
+		if _, ok := t.downUntil[target]; !ok {
+			t.downUntil[target] = time.Now().Add(failureEvictionPeriod)
+		}
+	}
+}
+
+func (t *failureTracker) markSuccess(target string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.failureCount, target)
+	delete(t.downUntil, target)
+}
+
+// live returns targets, excluding those the tracker considers down; if that
+// would leave nothing, it returns targets unfiltered (fail open).
+func (t *failureTracker) live(targets []string) []string {
+	live := make([]string, 0, len(targets))
+	for _, target := range targets {
+		if !t.down(target) {
+			live = append(live, target)
+		}
+	}
+	if len(live) == 0 {
+		return targets
+	}
+	return live
+}
+
+type randomPolicy struct {
+	failureTracker
+	targets []string
+}
+
+func (p *randomPolicy) Pick(key string) string {
+	live := p.live(p.targets)
+	if len(live) == 0 {
+		return ""
+	}
+	return live[rand.Intn(len(live))]
+}
+
+func (p *randomPolicy) MarkFailure(target string) { p.markFailure(target) }
+func (p *randomPolicy) MarkSuccess(target string) { p.markSuccess(target) }
+
+type roundRobinPolicy struct {
+	failureTracker
+	targets []string
+	next    uint64
+}
+
+func (p *roundRobinPolicy) Pick(key string) string {
+	live := p.live(p.targets)
+	if len(live) == 0 {
+		return ""
+	}
+	i := atomic.AddUint64(&p.next, 1) - 1
+	return live[i%uint64(len(live))]
+}
+
+func (p *roundRobinPolicy) MarkFailure(target string) { p.markFailure(target) }
+func (p *roundRobinPolicy) MarkSuccess(target string) { p.markSuccess(target) }
+
+// weightedTarget tracks one distinct target's static weight and the smooth
+// weighted round-robin's running "current weight", per Nginx's algorithm.
+type weightedTarget struct {
+	target        string
+	weight        int
+	currentWeight int
+}
+
+type weightedRoundRobinPolicy struct {
+	failureTracker
+	mu      sync.Mutex
+	targets []*weightedTarget
+}
+
+// newWeightedRoundRobinPolicy collapses repeated target addresses into a
+// single entry each, using the repeat count as that target's weight.
+func newWeightedRoundRobinPolicy(targets []string) *weightedRoundRobinPolicy {
+	order := make([]string, 0, len(targets))
+	weight := make(map[string]int, len(targets))
+
+	for _, t := range targets {
+		if weight[t] == 0 {
+			order = append(order, t)
+		}
+		weight[t]++
+	}
+
+	wts := make([]*weightedTarget, len(order))
+	for i, t := range order {
+		wts[i] = &weightedTarget{target: t, weight: weight[t]}
+	}
+
+	return &weightedRoundRobinPolicy{targets: wts}
+}
+
+func (p *weightedRoundRobinPolicy) Pick(key string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	live := make([]*weightedTarget, 0, len(p.targets))
+	for _, wt := range p.targets {
+		if !p.down(wt.target) {
+			live = append(live, wt)
+		}
+	}
+	if len(live) == 0 {
+		live = p.targets
+	}
+	if len(live) == 0 {
+		return ""
+	}
+
+	total := 0
+	var best *weightedTarget
+
+	for _, wt := range live {
+		wt.currentWeight += wt.weight
+		total += wt.weight
+
+		if best == nil || wt.currentWeight > best.currentWeight {
+			best = wt
+		}
+	}
+
+	best.currentWeight -= total
+
+	return best.target
+}
+
+func (p *weightedRoundRobinPolicy) MarkFailure(target string) { p.markFailure(target) }
+func (p *weightedRoundRobinPolicy) MarkSuccess(target string) { p.markSuccess(target) }
+
+type leastConnectionsPolicy struct {
+	failureTracker
+	targets []string
+
+	mu    sync.Mutex
+	conns map[string]int
+}
+
+func newLeastConnectionsPolicy(targets []string) *leastConnectionsPolicy {
+	return &leastConnectionsPolicy{
+		targets: targets,
+		conns:   make(map[string]int, len(targets)),
+	}
+}
+
+func (p *leastConnectionsPolicy) Pick(key string) string {
+	live := p.live(p.targets)
+	if len(live) == 0 {
+		return ""
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	best := live[0]
+	for _, target := range live[1:] {
+		if p.conns[target] < p.conns[best] {
+			best = target
+		}
+	}
+	p.conns[best]++
+
+	return best
+}
+
+func (p *leastConnectionsPolicy) release(target string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conns[target] > 0 {
+		p.conns[target]--
+	}
+}
+
+func (p *leastConnectionsPolicy) MarkFailure(target string) {
+	p.failureTracker.markFailure(target)
+	p.release(target)
+}
+
+func (p *leastConnectionsPolicy) MarkSuccess(target string) {
+	p.failureTracker.markSuccess(target)
+	p.release(target)
+}
+
+// maglevTableSize is the consistent-hash policy's lookup table size: a
+// prime well above any realistic target count, so the table stays close to
+// evenly filled and losing or adding a target only reshuffles ~1/n of it.
+const maglevTableSize = 65537
+
+type consistentHashPolicy struct {
+	failureTracker
+	targets []string
+	table   []string
+}
+
+func newConsistentHashPolicy(targets []string) *consistentHashPolicy {
+	return &consistentHashPolicy{
+		targets: targets,
+		table:   buildMaglevTable(targets),
+	}
+}
+
+// buildMaglevTable fills maglevTableSize slots with targets following
+// Google's Maglev paper: each target gets a pseudo-random permutation of
+// the table derived from its own hash, and targets take turns claiming
+// their next preferred, still-empty slot until the table is full.
+func buildMaglevTable(targets []string) []string {
+	n := len(targets)
+	if n == 0 {
+		return nil
+	}
+
+	permutation := make([][]int, n)
+	for i, t := range targets {
+		offset := maglevHash(t, 0) % maglevTableSize
+		skip := maglevHash(t, 1)%(maglevTableSize-1) + 1
+
+		perm := make([]int, maglevTableSize)
+		for j := range perm {
+			perm[j] = int((offset + uint64(j)*skip) % maglevTableSize)
+		}
+		permutation[i] = perm
+	}
+
+	table := make([]string, maglevTableSize)
+	filled := make([]bool, maglevTableSize)
+	next := make([]int, n)
+
+	for done := 0; done < maglevTableSize; {
+		for i := 0; i < n && done < maglevTableSize; i++ {
+			c := permutation[i][next[i]]
+			for filled[c] {
+				next[i]++
+				c = permutation[i][next[i]]
+			}
+
+			table[c] = targets[i]
+			filled[c] = true
+			next[i]++
+			done++
+		}
+	}
+
+	return table
+}
+
+func maglevHash(s string, seed byte) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte{seed})
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+func (p *consistentHashPolicy) Pick(key string) string {
+	live := p.live(p.targets)
+	if len(live) == 0 {
+		return ""
+	}
+
+	if key == "" || len(p.table) == 0 {
+		return live[rand.Intn(len(live))]
+	}
+
+	liveSet := make(map[string]bool, len(live))
+	for _, t := range live {
+		liveSet[t] = true
+	}
+
+	start := maglevHash(key, 2) % uint64(len(p.table))
+
+	for i := uint64(0); i < uint64(len(p.table)); i++ {
+		if t := p.table[(start+i)%uint64(len(p.table))]; liveSet[t] {
+			return t
+		}
+	}
+
+	return live[rand.Intn(len(live))]
+}
+
+func (p *consistentHashPolicy) MarkFailure(target string) { p.markFailure(target) }
+func (p *consistentHashPolicy) MarkSuccess(target string) { p.markSuccess(target) }
@@ -0,0 +1,28 @@
+package config
+
+// Snapshot is the configuration a single Provider contributes. Every
+// registered provider's latest Snapshot is merged, in registration order,
+// into the Config served to the rest of kingress whenever any of them
+// changes (see RegisterProvider and NotifyChanged).
+type Snapshot = Config
+
+// Provider is a source of backends/certificates: the Kubernetes watcher in
+// package k8s, a KV-store-backed provider, or anything else that can build
+// a Snapshot. A Provider registers its snapshot builder with
+// RegisterProvider, then watches for changes, calling NotifyChanged
+// whenever its Snapshot changed, until stop is closed.
+type Provider interface {
+	// Name identifies this provider in the merge order: if two providers
+	// define the same host/path, the one registered first wins and the
+	// later one is reported as a shadowed-duplicate warning.
+	Name() string
+
+	// Register calls RegisterProvider for this provider. The caller must
+	// call every provider's Register synchronously, in the order they
+	// should be merged in, before starting any of their Run in a
+	// goroutine -- registration order is otherwise left to the scheduler.
+	Register()
+
+	// Run starts watching for changes and blocks until stop is closed.
+	Run(stop <-chan struct{})
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"net"
+	"regexp"
+	"strings"
+)
+
+// RouteMatch holds the predicates and actions an IngressRoute-derived
+// Backend carries beyond the plain prefix matching shared with Ingress.
+// A nil RouteMatch (the common case for Ingress-sourced backends) matches
+// and rewrites nothing extra.
+type RouteMatch struct {
+	HostRegexp *regexp.Regexp
+	PathRegexp *regexp.Regexp
+	Method     string
+	Headers    []HeaderMatch
+
+	StripPrefix   string
+	AddPrefix     string
+	ReplaceRegexp *regexp.Regexp
+	ReplaceWith   string
+
+	BasicAuth   *BasicAuth
+	IPAllowList []*net.IPNet
+}
+
+type HeaderMatch struct {
+	Name   string
+	Regexp *regexp.Regexp
+}
+
+type BasicAuth struct {
+	Realm string
+	Users map[string]string
+}
+
+// MatchesRequest reports whether the extra predicates on m (if any) are
+// satisfied; a nil receiver always matches.
+func (m *RouteMatch) MatchesRequest(host, method, path string, header func(string) string) bool {
+	if m == nil {
+		return true
+	}
+
+	if m.HostRegexp != nil && !m.HostRegexp.MatchString(host) {
+		return false
+	}
+
+	if m.Method != "" && !strings.EqualFold(m.Method, method) {
+		return false
+	}
+
+	if m.PathRegexp != nil && !m.PathRegexp.MatchString(path) {
+		return false
+	}
+
+	for _, h := range m.Headers {
+		if h.Regexp == nil || !h.Regexp.MatchString(header(h.Name)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// RewritePath applies m's path rewrite action (if any) to path.
+func (m *RouteMatch) RewritePath(path string) string {
+	if m == nil {
+		return path
+	}
+
+	if m.ReplaceRegexp != nil {
+		return m.ReplaceRegexp.ReplaceAllString(path, m.ReplaceWith)
+	}
+
+	if m.StripPrefix != "" {
+		path = strings.TrimPrefix(path, m.StripPrefix)
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+	}
+
+	if m.AddPrefix != "" {
+		path = m.AddPrefix + path
+	}
+
+	return path
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+)
+
+// TLSProfile names a curated set of TLS parameters (minimum version, cipher
+// suites, curve preferences) applied to a `tls.Config`. Profiles are modeled
+// after Mozilla's server-side TLS recommendations.
+type TLSProfile string
+
+const (
+	// ProfileSecure only allows TLS 1.3, where the cipher suite is not
+	// configurable.
+	ProfileSecure TLSProfile = "secure"
+
+	// ProfileDefault allows TLS 1.2+ with a curated, modern cipher list
+	// (ECDHE with AES-GCM/ChaCha20-Poly1305 only).
+	ProfileDefault TLSProfile = "default"
+
+	// ProfileLegacy allows TLS 1.2+ with a broader cipher list, for older
+	// clients that can't negotiate the default profile.
+	ProfileLegacy TLSProfile = "legacy"
+)
+
+var modernCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
+}
+
+var legacyCipherSuites = append(append([]uint16{}, modernCipherSuites...),
+	tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
+	tls.TLS_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_RSA_WITH_AES_128_CBC_SHA,
+	tls.TLS_RSA_WITH_AES_256_CBC_SHA,
+)
+
+var modernCurves = []tls.CurveID{
+	tls.X25519,
+	tls.CurveP256,
+	tls.CurveP384,
+}
+
+// TLSConfig builds a fresh `*tls.Config` for this profile. Unknown profiles
+// fall back to ProfileDefault.
+func (p TLSProfile) TLSConfig() *tls.Config {
+	switch p {
+	case ProfileSecure:
+		return &tls.Config{
+			MinVersion:       tls.VersionTLS13,
+			CurvePreferences: modernCurves,
+		}
+
+	case ProfileLegacy:
+		return &tls.Config{
+			MinVersion:               tls.VersionTLS12,
+			CipherSuites:             legacyCipherSuites,
+			CurvePreferences:         modernCurves,
+			PreferServerCipherSuites: true,
+		}
+
+	default:
+		return &tls.Config{
+			MinVersion:               tls.VersionTLS12,
+			CipherSuites:             modernCipherSuites,
+			CurvePreferences:         modernCurves,
+			PreferServerCipherSuites: true,
+		}
+	}
+}
+
+// Valid tells if p is one of the known profiles.
+func (p TLSProfile) Valid() bool {
+	switch p {
+	case ProfileSecure, ProfileDefault, ProfileLegacy:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseTLSProfile validates a profile name as given on the `-tls-profile`
+// flag or the `tls-profile` ingress annotation.
+func ParseTLSProfile(value string) (TLSProfile, error) {
+	p := TLSProfile(value)
+	if !p.Valid() {
+		return "", fmt.Errorf("unknown TLS profile %q (known: %s, %s, %s)", value, ProfileSecure, ProfileDefault, ProfileLegacy)
+	}
+	return p, nil
+}
+
+var minVersionByName = map[string]uint16{
+	"TLSv1.0": tls.VersionTLS10,
+	"TLSv1.1": tls.VersionTLS11,
+	"TLSv1.2": tls.VersionTLS12,
+	"TLSv1.3": tls.VersionTLS13,
+}
+
+// ParseMinTLSVersion parses the ssl-min-protocol-version annotation value
+// ("TLSv1.2" or "TLSv1.3") into the tls.Config.MinVersion it maps to.
+// SSLv3/TLSv1.0/TLSv1.1 are rejected unconditionally: kingress never
+// negotiates below TLS 1.2, regardless of what an ingress asks for.
+func ParseMinTLSVersion(value string) (uint16, error) {
+	v, ok := minVersionByName[value]
+	if !ok {
+		return 0, fmt.Errorf("unknown TLS protocol version %q (known: TLSv1.2, TLSv1.3)", value)
+	}
+	if v < tls.VersionTLS12 {
+		return 0, fmt.Errorf("TLS protocol version %q is below the minimum kingress allows (TLSv1.2)", value)
+	}
+	return v, nil
+}
+
+// cipherSuiteByName maps Go's cipher suite names to their tls.CipherSuite
+// ID, restricted to the suites Go's crypto/tls itself considers secure (see
+// tls.CipherSuites()); the legacy/insecure ones aren't offered here.
+// OpenSSL-style names (e.g. "ECDHE-RSA-AES128-GCM-SHA256") are not
+// recognized.
+var cipherSuiteByName = func() map[string]uint16 {
+	m := make(map[string]uint16, len(tls.CipherSuites()))
+	for _, cs := range tls.CipherSuites() {
+		m[cs.Name] = cs.ID
+	}
+	return m
+}()
+
+// ParseCipherSuites parses the ssl-ciphers annotation value, a comma-separated
+// list of Go cipher suite names (e.g. "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"),
+// rejecting unknown tokens.
+func ParseCipherSuites(value string) ([]uint16, error) {
+	names := strings.Split(value, ",")
+	suites := make([]uint16, 0, len(names))
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		id, ok := cipherSuiteByName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown or unsupported TLS cipher suite %q", name)
+		}
+
+		suites = append(suites, id)
+	}
+
+	return suites, nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mcluseau/kingress/config"
+)
+
+// defaultCORSAllowMethods is used for Access-Control-Allow-Methods when the
+// cors-allow-methods annotation isn't set, matching ingress-nginx's default.
+var defaultCORSAllowMethods = []string{"GET", "PUT", "POST", "DELETE", "PATCH", "OPTIONS"}
+
+// applyCORS implements the enable-cors/cors-* annotation family: it adds the
+// CORS response headers for requests carrying an allowed Origin, and fully
+// answers OPTIONS preflights itself. It reports whether it already wrote a
+// response (a preflight), in which case the caller must not forward the
+// request to the backend.
+func applyCORS(backend *config.Backend, w http.ResponseWriter, r *http.Request) (handled bool) {
+	opts := backend.Options
+
+	if !opts.CORSEnabled && len(opts.CORSAllowedOrigins) == 0 {
+		return false
+	}
+
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+
+	if len(opts.CORSAllowedOrigins) != 0 && !corsOriginAllowed(opts.CORSAllowedOrigins, origin) {
+		return false
+	}
+
+	hdr := w.Header()
+	hdr.Set("Access-Control-Allow-Origin", origin)
+	hdr.Add("Vary", "Origin")
+
+	if opts.CORSAllowCredentials {
+		hdr.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if len(opts.CORSExposeHeaders) != 0 {
+		hdr.Set("Access-Control-Expose-Headers", strings.Join(opts.CORSExposeHeaders, ","))
+	}
+
+	if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+		// not a preflight: the headers above are enough, let the request
+		// through to the backend.
+		return false
+	}
+
+	methods := opts.CORSAllowMethods
+	if len(methods) == 0 {
+		methods = defaultCORSAllowMethods
+	}
+	hdr.Set("Access-Control-Allow-Methods", strings.Join(methods, ","))
+
+	if len(opts.CORSAllowHeaders) != 0 {
+		hdr.Set("Access-Control-Allow-Headers", strings.Join(opts.CORSAllowHeaders, ","))
+	} else if reqHeaders := r.Header.Get("Access-Control-Request-Headers"); reqHeaders != "" {
+		hdr.Set("Access-Control-Allow-Headers", reqHeaders)
+	}
+
+	if opts.CORSMaxAge > 0 {
+		hdr.Set("Access-Control-Max-Age", strconv.FormatInt(int64(opts.CORSMaxAge/time.Second), 10))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return true
+}
+
+// corsOriginAllowed matches origin against allowed, supporting a leading "*"
+// as a wildcard-subdomain prefix (e.g. "*.example.com").
+func corsOriginAllowed(allowed []string, origin string) bool {
+	originURL, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	for _, allowedOrigin := range allowed {
+		if len(allowedOrigin) == 0 {
+			continue
+		}
+
+		if suffix, hasWildcardPrefix := strings.CutPrefix(allowedOrigin, "*"); hasWildcardPrefix {
+			if strings.HasSuffix(originURL.Hostname(), suffix) {
+				return true
+			}
+		} else if origin == allowedOrigin {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"crypto/tls"
 	"flag"
 	"fmt"
 	"io"
@@ -10,6 +9,7 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/mcluseau/kingress/config"
 	"github.com/mcluseau/kingress/proxier"
 )
 
@@ -29,10 +29,11 @@ func main() {
 
 	proxy := proxier.New()
 
+	tlsCfg := config.ProfileDefault.TLSConfig()
+	tlsCfg.InsecureSkipVerify = true
+
 	proxy.AddHandlers(proxier.TLSForwardHandler{
-		Config: &tls.Config{
-			InsecureSkipVerify: true,
-		},
+		Config:  tlsCfg,
 		Network: "tcp",
 		Target:  "127.0.0.1:443",
 	})
@@ -8,6 +8,7 @@ import (
 	"sync"
 
 	"github.com/mcluseau/kingress/config"
+	"github.com/mcluseau/kingress/metrics"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
@@ -77,7 +78,17 @@ func (h *oxyHandler) proxyGRPCStream(srv interface{}, src grpc.ServerStream) (er
 	}
 
 	backend := ctx.Value("backend").(*config.Backend)
-	target := backend.Target()
+	target := backend.TargetFor(peer.Addr.String())
+
+	// :authority carries the virtual host the client dialed (set from
+	// r.Host by grpc.Server.ServeHTTP, see transport.NewServerHandlerTransport),
+	// not the backend pod address -- metrics' "host" label means the same
+	// thing here as it does for HTTP traffic (oxy-handler.go's r.Host), so
+	// it stays bounded instead of growing one series per pod.
+	host := target
+	if authority := md.Get(":authority"); len(authority) > 0 {
+		host = authority[0]
+	}
 
 	var tlsDialOpt grpc.DialOption
 
@@ -93,10 +104,24 @@ func (h *oxyHandler) proxyGRPCStream(srv interface{}, src grpc.ServerStream) (er
 	conn, err := grpc.DialContext(ctx, target, tlsDialOpt, grpc.WithCodec(rawPbCodec{}))
 	if err != nil {
 		log.Print("failed to connect to ", target, ": ", err)
+		backend.Policy().MarkFailure(target)
+		metrics.ObserveBackendError(backend.IngressRef, host, "grpc")
 		return errBackendUnavailable
 	}
 	defer conn.Close()
 
+	metrics.IncInFlight(backend.IngressRef, host, "grpc")
+	defer metrics.DecInFlight(backend.IngressRef, host, "grpc")
+
+	defer func() {
+		if err != nil {
+			backend.Policy().MarkFailure(target)
+			metrics.ObserveBackendError(backend.IngressRef, host, "grpc")
+		} else {
+			backend.Policy().MarkSuccess(target)
+		}
+	}()
+
 	md.Set("x-forwarded-for", peer.Addr.String())
 
 	clientCtx, cancel := context.WithCancel(ctx)
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mcluseau/kingress/config"
 )
@@ -21,8 +22,42 @@ func portOfBind(bind string) string {
 	return strconv.Itoa(addr.Port)
 }
 
-// Returns "" iff the request can be forwarded to the backend, the reject reason otherwise
-func allowRequest(backend *config.Backend, handlerProto string, w http.ResponseWriter, r *http.Request) string {
+// Returns "" iff the request can be forwarded to the backend, the reject
+// reason and the status sent to the client otherwise.
+func allowRequest(backend *config.Backend, handlerProto string, w http.ResponseWriter, r *http.Request) (reject string, status int) {
+	if backend.Match != nil && backend.Match.IPAllowList != nil {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			panic(err) // not possible (built by net/http)
+		}
+
+		remoteIP := net.ParseIP(host)
+
+		accessOk := false
+		for _, ipnet := range backend.Match.IPAllowList {
+			if ipnet.Contains(remoteIP) {
+				accessOk = true
+				break
+			}
+		}
+
+		if !accessOk {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return "rejecting (not in IP allow list)", http.StatusForbidden
+		}
+	}
+
+	if backend.Match != nil && backend.Match.BasicAuth != nil {
+		user, pass, ok := r.BasicAuth()
+		wantPass, known := backend.Match.BasicAuth.Users[user]
+
+		if !ok || !known || wantPass != pass {
+			w.Header().Set("WWW-Authenticate", `Basic realm="`+backend.Match.BasicAuth.Realm+`"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return "rejecting (basic auth failed)", http.StatusUnauthorized
+		}
+	}
+
 	// check for whitelist
 	if backend.Options.WhitelistSourceRange != nil {
 		host, _, err := net.SplitHostPort(r.RemoteAddr)
@@ -45,17 +80,28 @@ func allowRequest(backend *config.Backend, handlerProto string, w http.ResponseW
 
 		if !accessOk {
 			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
-			return "rejecting (not in whitelist)"
+			return "rejecting (not in whitelist)", http.StatusForbidden
 		}
 	}
 
 	// check for SSL redirection
 	if backend.Options.SSLRedirect && handlerProto != "https" {
 		redirectToHTTPS(w, r)
-		return "redirecting to HTTPS"
+		return "redirecting to HTTPS", http.StatusMovedPermanently
+	}
+
+	// check rate limits (limit-rps/limit-rpm/limit-connections)
+	if backend.Options.LimitRPS > 0 || backend.Options.LimitRPM > 0 || backend.Options.LimitConnections > 0 {
+		clientIP := rateLimitClientIP(r)
+
+		if ok, retryAfter := limiterFor(backend).allow(backend.Options, clientIP); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Round(time.Second)/time.Second)+1))
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+			return "rejecting (rate limit exceeded)", http.StatusTooManyRequests
+		}
 	}
 
-	return ""
+	return "", 0
 }
 
 // returns target and http status if no target is found
@@ -71,11 +117,26 @@ func getBackend(r *http.Request) (*config.Backend, string, int) {
 	}
 
 	for _, backend := range backends {
-		if !backend.HandlesPath(r.RequestURI) {
+		if !backend.HandlesPath(r.RequestURI) || !backend.Match.MatchesRequest(hostWithoutPort, r.Method, r.RequestURI, r.Header.Get) {
+			continue
+		}
+
+		target := backend.TargetFor(lbAffinityKey(backend, r))
+		if target == "" {
+			return nil, "", http.StatusServiceUnavailable
+		}
+
+		return backend, target, 0
+	}
+
+	// no exact/wildcard host backend matched; try the IngressRoute backends
+	// routed by HostRegexp instead
+	for _, backend := range config.Current.RouteBackends {
+		if !backend.HandlesPath(r.RequestURI) || !backend.Match.MatchesRequest(hostWithoutPort, r.Method, r.RequestURI, r.Header.Get) {
 			continue
 		}
 
-		target := backend.Target()
+		target := backend.TargetFor(lbAffinityKey(backend, r))
 		if target == "" {
 			return nil, "", http.StatusServiceUnavailable
 		}
@@ -85,3 +146,19 @@ func getBackend(r *http.Request) (*config.Backend, string, int) {
 
 	return nil, "", http.StatusNotFound
 }
+
+// lbAffinityKey returns the key a consistent-hash lb-policy should route on:
+// the configured header's value, falling back to the client's source IP.
+func lbAffinityKey(backend *config.Backend, r *http.Request) string {
+	if header := backend.Options.LBHashHeader; header != "" {
+		if v := r.Header.Get(header); v != "" {
+			return v
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
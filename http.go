@@ -17,9 +17,13 @@ func startHTTP(bind string) error {
 		return err
 	}
 
+	listener = maybeProxyProtocol(listener)
+
 	log.Print("http: listening on ", bind)
 
-	if err := http.Serve(listener, &HttpHandler{"http", portOfBind(bind)}); err != nil {
+	handler := acmeHTTPHandler(newHandler("http", portOfBind(bind)))
+
+	if err := http.Serve(listener, handler); err != nil {
 		log.Fatal("http: serve error: ", err)
 	}
 
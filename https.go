@@ -4,13 +4,17 @@ import (
 	"crypto/tls"
 	"flag"
 	"log"
+	"net"
 	"net/http"
+	"strings"
 
 	"github.com/mcluseau/kingress/config"
+	"github.com/mcluseau/kingress/metrics"
 )
 
 var (
-	debugTLS = false
+	debugTLS   = false
+	tlsProfile = flag.String("tls-profile", string(config.ProfileDefault), "Default TLS profile for the HTTPS listener (secure, default or legacy); can be overridden per-ingress with the tls-profile annotation")
 )
 
 func init() {
@@ -18,15 +22,26 @@ func init() {
 }
 
 func startHTTPS(bind string) error {
-	config := &tls.Config{
-		GetCertificate: getCertificate,
+	defaultProfile, err := config.ParseTLSProfile(*tlsProfile)
+	if err != nil {
+		return err
+	}
+
+	log.Print("https: TLS profile: ", defaultProfile)
+
+	tlsCfg := &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			return tlsConfigForHello(hello, defaultProfile), nil
+		},
 	}
 
-	listener, err := tls.Listen("tcp", bind, config)
+	tcpListener, err := net.Listen("tcp", bind)
 	if err != nil {
 		return err
 	}
 
+	listener := tls.NewListener(maybeProxyProtocol(tcpListener), tlsCfg)
+
 	log.Print("https: listening on ", bind)
 
 	if err := http.Serve(listener, newHandler("https", portOfBind(bind))); err != nil {
@@ -36,10 +51,89 @@ func startHTTPS(bind string) error {
 	return nil
 }
 
+// tlsConfigForHello builds the effective TLS config for a single handshake,
+// applying the per-ingress tls-profile annotation when the first backend
+// for the requested SNI host (see firstBackendForHost) sets it.
+func tlsConfigForHello(hello *tls.ClientHelloInfo, defaultProfile config.TLSProfile) *tls.Config {
+	profile := defaultProfile
+	backend := firstBackendForHost(hello.ServerName)
+
+	if backend != nil && backend.Options.TLSProfile != "" {
+		profile = backend.Options.TLSProfile
+	}
+
+	cfg := profile.TLSConfig()
+	cfg.GetCertificate = getCertificate
+
+	if backend != nil {
+		applySSLOverrides(cfg, backend.Options, "https")
+	}
+
+	// VerifyConnection runs once the handshake itself has succeeded, so
+	// this only observes successes; a failed handshake (e.g. no shared
+	// cipher suite) never reaches it and goes uncounted.
+	cfg.VerifyConnection = func(state tls.ConnectionState) error {
+		metrics.ObserveTLSHandshake(tlsVersionName(state.Version), true)
+		return nil
+	}
+
+	return cfg
+}
+
+// applySSLOverrides applies a backend's ssl-min-protocol-version/ssl-ciphers
+// annotations onto cfg, logging under logPrefix and dropping the cipher
+// list (rather than erroring) if the effective minimum version ends up
+// being TLS 1.3, where the cipher suite isn't configurable.
+func applySSLOverrides(cfg *tls.Config, opts config.BackendOptions, logPrefix string) {
+	if opts.SSLMinProtocolVersion != 0 {
+		cfg.MinVersion = opts.SSLMinProtocolVersion
+	}
+
+	// Never let an override drop below TLS 1.2: SSLv3/TLS1.0/1.1 are denied
+	// unconditionally, regardless of how cfg.MinVersion ended up set.
+	if cfg.MinVersion < tls.VersionTLS12 {
+		cfg.MinVersion = tls.VersionTLS12
+	}
+
+	if len(opts.SSLCipherSuites) == 0 {
+		return
+	}
+
+	if cfg.MinVersion >= tls.VersionTLS13 {
+		log.Printf("%s: warning: ssl-ciphers ignored: TLS 1.3 cipher suites aren't configurable", logPrefix)
+		return
+	}
+
+	cfg.CipherSuites = opts.SSLCipherSuites
+}
+
+func firstBackendForHost(host string) *config.Backend {
+	backends := config.Current.HostBackends[host]
+
+	if backends == nil {
+		if n := strings.Index(host, "."); n > 0 {
+			backends = config.Current.HostBackends["*"+host[n:]]
+		}
+	}
+
+	if len(backends) == 0 {
+		return nil
+	}
+
+	return backends[0]
+}
+
 func getCertificate(helloInfo *tls.ClientHelloInfo) (*tls.Certificate, error) {
 	certificate, ok := config.Current.HostCerts[helloInfo.ServerName]
 
 	if !ok {
+		if acmeHostEligible(helloInfo.ServerName) {
+			// blocks this handshake on the ACME exchange the first time;
+			// once issued, the cert is persisted to its Secret and served
+			// from HostCerts like any other from then on.
+			return acmeCertificate(helloInfo)
+		}
+
 		if debugTLS {
 			log.Printf("https: using default certificate for %q", helloInfo.ServerName)
 		}
@@ -10,7 +10,47 @@ import (
 
 func newConfig() config.Config {
 	newBackends := config.Backends{}
+	newRouteBackends := make([]*config.Backend, 0)
 	errors := make([]string, 0)
+	liveTargets := map[string]bool{}
+
+	// IngressRoute-derived backends are built first: the plain-Ingress loop
+	// below skips a host/path that's already taken, so CRD routes win over
+	// Ingress ones for the same host/path.
+	for irRef, rules := range ingressRouteRules {
+		for _, rule := range rules {
+			targetPort, ok := findTargetPort(rule.Service, rule.Port)
+			if !ok {
+				continue
+			}
+
+			allTargets := findEndpoints(rule.Service, targetPort)
+			for _, target := range allTargets {
+				liveTargets[target] = true
+			}
+			registerHealthTargets(allTargets, config.BackendOptions{})
+
+			backend := config.NewBackend(irRef, rule.Prefix, healthyEndpoints(allTargets)...)
+			backend.Match = rule.Match
+
+			if rule.Match.HostRegexp != nil {
+				newRouteBackends = append(newRouteBackends, backend)
+				continue
+			}
+
+			newBackends[rule.Host] = append(newBackends[rule.Host], backend)
+		}
+	}
+
+	// Gateway API HTTPRoute/TLSRoute-derived backends come next: they lose
+	// to IngressRoute but win over plain Ingress for the same host/path,
+	// same precedence rule as above.
+	for routeRef, rules := range httpRouteRules {
+		mergeGatewayRouteRules(newBackends, routeRef, rules, &errors, liveTargets)
+	}
+	for routeRef, rules := range tlsRouteRules {
+		mergeGatewayRouteRules(newBackends, routeRef, rules, &errors, liveTargets)
+	}
 
 	for ingRef, rules := range ingressRules {
 	rulesLoop:
@@ -34,23 +74,41 @@ func newConfig() config.Config {
 				continue
 			}
 
-			// build the backend from the service endpoints
-			backend := config.NewBackend(ingRef, rule.Path, findEndpoints(rule.Service, targetPort)...)
-
+			var opts config.BackendOptions
 			if rule.Options != nil {
-				backend.Options = *rule.Options
+				opts = *rule.Options
 			}
 
+			allTargets := findEndpoints(rule.Service, targetPort)
+			for _, target := range allTargets {
+				liveTargets[target] = true
+			}
+			registerHealthTargets(allTargets, opts)
+
+			// build the backend from the service endpoints, excluding those
+			// the active health checker has marked down
+			backend := config.NewBackend(ingRef, rule.Path, healthyEndpoints(allTargets)...)
+			backend.Options = opts
+
 			newBackends[rule.Host] = append(backends, backend)
 		}
 	}
 
+	pruneHealthTargets(liveTargets)
+
 	// Sort each host's backends by reverse length
 	for _, backends := range newBackends {
 		sort.Sort(backendsOrder(backends))
 	}
 
 	newCerts := map[string]*tls.Certificate{}
+
+	gatewaysMu.Lock()
+	for _, gw := range gateways {
+		gatewayListenerCerts(gw, newCerts)
+	}
+	gatewaysMu.Unlock()
+
 	for ingRef, ingTLSs := range ingressSecrets {
 		for _, ingTLS := range ingTLSs {
 			cert, ok := secretCertificate[ingTLS.SecretRef]
@@ -68,9 +126,85 @@ func newConfig() config.Config {
 	sort.Strings(errors)
 
 	return config.Config{
-		Errors:       errors,
-		HostBackends: newBackends,
-		HostCerts:    newCerts,
-		DefaultCert:  defaultCert,
+		Errors:        errors,
+		HostBackends:  newBackends,
+		HostCerts:     newCerts,
+		RouteBackends: newRouteBackends,
+		DefaultCert:   defaultCert,
 	}
 }
+
+// maxWeightRepeat caps how many times a single backendRef's endpoints are
+// repeated in a Backend's flat Targets slice to approximate its relative
+// weight (see resolveWeightedTargets): high ratios (e.g. 100:1) are clamped
+// rather than blowing up the targets slice.
+const maxWeightRepeat = 10
+
+// mergeGatewayRouteRules folds routeRef's HTTPRoute/TLSRoute-derived rules
+// into newBackends, skipping (and recording as an error) any host/path
+// already claimed by an earlier provider, the same precedence rule the
+// Ingress loop above applies against IngressRoute.
+func mergeGatewayRouteRules(newBackends config.Backends, routeRef string, rules []httpRouteRule, errors *[]string, liveTargets map[string]bool) {
+rulesLoop:
+	for _, rule := range rules {
+		for _, backend := range newBackends[rule.Host] {
+			if backend.Prefix == rule.Prefix {
+				*errors = append(*errors, fmt.Sprintf(
+					"warning: duplicate definition for host %s, path %v: ignoring route %s",
+					rule.Host, rule.Prefix, routeRef))
+				continue rulesLoop
+			}
+		}
+
+		allTargets := resolveWeightedTargets(rule.Refs)
+		if len(allTargets) == 0 {
+			continue
+		}
+
+		for _, target := range allTargets {
+			liveTargets[target] = true
+		}
+		registerHealthTargets(allTargets, config.BackendOptions{})
+
+		backend := config.NewBackend(routeRef, rule.Prefix, healthyEndpoints(allTargets)...)
+		backend.Match = rule.Match
+
+		newBackends[rule.Host] = append(newBackends[rule.Host], backend)
+	}
+}
+
+// resolveWeightedTargets resolves each backendRef to its service endpoints
+// and repeats them proportionally to Weight (clamped to maxWeightRepeat),
+// so the flat Targets slice approximates the configured traffic split
+// under the existing random/round-robin BackendPolicy implementations,
+// without introducing a weighted load-balancing policy of its own.
+func resolveWeightedTargets(refs []httpRouteBackendRef) []string {
+	var allTargets []string
+
+	for _, ref := range refs {
+		if ref.Weight <= 0 {
+			continue
+		}
+
+		targetPort, ok := findTargetPort(ref.Service, ref.Port)
+		if !ok {
+			continue
+		}
+
+		endpoints := findEndpoints(ref.Service, targetPort)
+		if len(endpoints) == 0 {
+			continue
+		}
+
+		repeat := int(ref.Weight)
+		if repeat > maxWeightRepeat {
+			repeat = maxWeightRepeat
+		}
+
+		for i := 0; i < repeat; i++ {
+			allTargets = append(allTargets, endpoints...)
+		}
+	}
+
+	return allTargets
+}
@@ -0,0 +1,159 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	gatewayv1 "github.com/mcluseau/kingress/apis/gateway/v1"
+	"github.com/mcluseau/kingress/config"
+	restclient "k8s.io/client-go/rest"
+)
+
+var (
+	gateways   = map[string]*gatewayv1.Gateway{}
+	gatewaysMu sync.Mutex
+)
+
+type gatewayHandler struct {
+	Client  restclient.Interface
+	LBHosts []string
+}
+
+func (h gatewayHandler) OnAdd(obj any) {
+	h.update(obj.(*gatewayv1.Gateway))
+}
+
+func (h gatewayHandler) OnUpdate(_, newObj any) {
+	h.update(newObj.(*gatewayv1.Gateway))
+}
+
+func (h gatewayHandler) OnDelete(obj any) {
+	h.delete(obj.(*gatewayv1.Gateway))
+}
+
+func (h gatewayHandler) update(gw *gatewayv1.Gateway) {
+	if !ownsGatewayClass(gw.Spec.GatewayClassName) {
+		// not ours (anymore): drop it and don't touch its status
+		h.delete(gw)
+		return
+	}
+
+	ref := k8sRef(gw)
+
+	gatewaysMu.Lock()
+	gateways[ref] = gw
+	gatewaysMu.Unlock()
+
+	config.NotifyChanged()
+
+	// also check & update the status as needed, symmetric to ingressHandler
+	addrs := make([]gatewayv1.GatewayStatusAddress, 0, len(h.LBHosts))
+	for _, host := range h.LBHosts {
+		addrType := "Hostname"
+		if net.ParseIP(host) != nil {
+			addrType = "IPAddress"
+		}
+
+		addrs = append(addrs, gatewayv1.GatewayStatusAddress{Type: addrType, Value: host})
+	}
+
+	curBytes, _ := json.Marshal(gw.Status.Addresses)
+	newBytes, _ := json.Marshal(addrs)
+	if bytes.Equal(curBytes, newBytes) {
+		return
+	}
+
+	log.Print("updating gateway status: ", ref, ": ", string(newBytes))
+
+	updated := gw.DeepCopy()
+	updated.Status.Addresses = addrs
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := h.Client.Put().
+		Namespace(gw.Namespace).
+		Resource("gateways").
+		Name(gw.Name).
+		SubResource("status").
+		Body(updated).
+		Do(ctx).
+		Error()
+	if err != nil {
+		log.Print("failed to update gateway status: ", ref, ": ", err)
+	}
+}
+
+func (_ gatewayHandler) delete(gw *gatewayv1.Gateway) {
+	ref := k8sRef(gw)
+
+	gatewaysMu.Lock()
+	delete(gateways, ref)
+	gatewaysMu.Unlock()
+
+	config.NotifyChanged()
+}
+
+// gatewayListenerHostnames returns the hostnames declared by gw's listeners,
+// for HTTPRoutes/TLSRoutes that don't set their own spec.hostnames.
+func gatewayListenerHostnames(gw *gatewayv1.Gateway) []string {
+	var hosts []string
+	for _, l := range gw.Spec.Listeners {
+		if l.Hostname != nil && *l.Hostname != "" {
+			hosts = append(hosts, *l.Hostname)
+		}
+	}
+	return hosts
+}
+
+// gatewayListenerCerts resolves gw's listeners' TLS certificateRefs to
+// already-synced TLS secrets, keyed by the listener's hostname.
+func gatewayListenerCerts(gw *gatewayv1.Gateway, out map[string]*tls.Certificate) {
+	for _, l := range gw.Spec.Listeners {
+		if l.TLS == nil || l.Hostname == nil || *l.Hostname == "" {
+			continue
+		}
+
+		for _, ref := range l.TLS.CertificateRefs {
+			ns := gw.Namespace
+			if ref.Namespace != nil && *ref.Namespace != "" {
+				ns = *ref.Namespace
+			}
+
+			cert, ok := secretCertificate[ns+"/"+ref.Name]
+			if !ok {
+				continue
+			}
+
+			out[*l.Hostname] = cert
+			break
+		}
+	}
+}
+
+// parentGateways resolves refs (an HTTPRoute's or TLSRoute's parentRefs) to
+// the Gateways we own among those this instance currently watches,
+// defaulting a ref's namespace to defaultNS (the route's own namespace).
+func parentGateways(refs []gatewayv1.ParentReference, defaultNS string) []*gatewayv1.Gateway {
+	gatewaysMu.Lock()
+	defer gatewaysMu.Unlock()
+
+	var out []*gatewayv1.Gateway
+	for _, ref := range refs {
+		ns := defaultNS
+		if ref.Namespace != nil && *ref.Namespace != "" {
+			ns = *ref.Namespace
+		}
+
+		if gw, ok := gateways[ns+"/"+ref.Name]; ok {
+			out = append(out, gw)
+		}
+	}
+	return out
+}
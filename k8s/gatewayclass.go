@@ -0,0 +1,56 @@
+package k8s
+
+import (
+	"flag"
+	"sync"
+
+	gatewayv1 "github.com/mcluseau/kingress/apis/gateway/v1"
+	"github.com/mcluseau/kingress/config"
+)
+
+var (
+	gatewayControllerName = flag.String("gateway-controller-name", "kingress.mcluseau.github.io/gateway-controller",
+		"controller name a GatewayClass must declare (in spec.controllerName) to be honored")
+
+	gatewayClasses   = map[string]*gatewayv1.GatewayClass{}
+	gatewayClassesMu sync.Mutex
+)
+
+type gatewayClassHandler struct{}
+
+func (h gatewayClassHandler) OnAdd(obj any) {
+	h.update(obj.(*gatewayv1.GatewayClass))
+}
+
+func (h gatewayClassHandler) OnUpdate(_, newObj any) {
+	h.update(newObj.(*gatewayv1.GatewayClass))
+}
+
+func (gatewayClassHandler) OnDelete(obj any) {
+	gc := obj.(*gatewayv1.GatewayClass)
+
+	gatewayClassesMu.Lock()
+	delete(gatewayClasses, gc.Name)
+	gatewayClassesMu.Unlock()
+
+	config.NotifyChanged()
+}
+
+func (gatewayClassHandler) update(gc *gatewayv1.GatewayClass) {
+	gatewayClassesMu.Lock()
+	gatewayClasses[gc.Name] = gc
+	gatewayClassesMu.Unlock()
+
+	config.NotifyChanged()
+}
+
+// ownsGatewayClass reports whether className names a GatewayClass this
+// instance watches and that declares -gateway-controller-name, the same
+// way matchesIngressClass resolves ownership for Ingresses.
+func ownsGatewayClass(className string) bool {
+	gatewayClassesMu.Lock()
+	gc, ok := gatewayClasses[className]
+	gatewayClassesMu.Unlock()
+
+	return ok && gc.Spec.ControllerName == *gatewayControllerName
+}
@@ -0,0 +1,175 @@
+package k8s
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mcluseau/kingress/config"
+)
+
+const (
+	defaultHealthCheckInterval = 10 * time.Second
+	defaultHealthCheckTimeout  = 2 * time.Second
+)
+
+// healthProbe is the active health-check configuration for one endpoint
+// target, as derived from its backend's options.
+type healthProbe struct {
+	path     string
+	interval time.Duration
+	timeout  time.Duration
+
+	lastProbe time.Time
+}
+
+var (
+	healthMu     sync.Mutex
+	healthProbes = map[string]*healthProbe{}
+	healthy      = map[string]bool{}
+)
+
+// registerHealthTargets declares the targets that should be actively probed
+// for a backend built from the given options. Targets no longer present in
+// any backend are pruned on the next pruneHealthTargets call from newConfig.
+func registerHealthTargets(targets []string, opts config.BackendOptions) {
+	interval := opts.HealthCheckInterval
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+
+	timeout := opts.HealthCheckTimeout
+	if timeout <= 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+
+	healthMu.Lock()
+	defer healthMu.Unlock()
+
+	for _, target := range targets {
+		probe, ok := healthProbes[target]
+		if !ok {
+			probe = &healthProbe{}
+			healthProbes[target] = probe
+		}
+
+		probe.path = opts.HealthCheckPath
+		probe.interval = interval
+		probe.timeout = timeout
+	}
+}
+
+// pruneHealthTargets drops probe state for targets no longer referenced by
+// any backend, so the checker doesn't keep polling stale pod IPs forever.
+func pruneHealthTargets(liveTargets map[string]bool) {
+	healthMu.Lock()
+	defer healthMu.Unlock()
+
+	for target := range healthProbes {
+		if !liveTargets[target] {
+			delete(healthProbes, target)
+			delete(healthy, target)
+		}
+	}
+}
+
+// isHealthy reports whether a target should be considered for routing.
+// Targets with no probe result yet are assumed healthy (fail open) so a
+// fresh endpoint isn't excluded before its first probe completes.
+func isHealthy(target string) bool {
+	healthMu.Lock()
+	defer healthMu.Unlock()
+
+	h, known := healthy[target]
+	return !known || h
+}
+
+// HealthStatus returns a snapshot of the active health checker's view of
+// each probed target, for display on the /api endpoint.
+func HealthStatus() map[string]bool {
+	healthMu.Lock()
+	defer healthMu.Unlock()
+
+	out := make(map[string]bool, len(healthy))
+	for target, h := range healthy {
+		out[target] = h
+	}
+	return out
+}
+
+// startHealthChecker runs until stopCh is closed, probing due targets on a
+// fixed tick and recording their result.
+func startHealthChecker() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case now := <-ticker.C:
+			healthMu.Lock()
+			due := make(map[string]healthProbe, len(healthProbes))
+			for target, probe := range healthProbes {
+				if now.Sub(probe.lastProbe) < probe.interval {
+					continue
+				}
+				probe.lastProbe = now
+				due[target] = *probe
+			}
+			healthMu.Unlock()
+
+			for target, probe := range due {
+				go probeTarget(target, probe)
+			}
+		}
+	}
+}
+
+func probeTarget(target string, probe healthProbe) {
+	ok := probeOnce(target, probe)
+
+	healthMu.Lock()
+	prev, known := healthy[target]
+	healthy[target] = ok
+	healthMu.Unlock()
+
+	// Rebuild Config on a transition so a target that starts failing or
+	// recovers is excluded/restored promptly, instead of only taking
+	// effect the next time something else (an ingress/service edit, the
+	// resync) happens to trigger newConfig. A target with no prior result
+	// is treated as healthy (see isHealthy's fail-open), so only its first
+	// *failing* probe counts as a transition.
+	wasHealthy := !known || prev
+	if wasHealthy != ok {
+		config.NotifyChanged()
+	}
+}
+
+func probeOnce(target string, probe healthProbe) bool {
+	if probe.path == "" {
+		conn, err := net.DialTimeout("tcp", target, probe.timeout)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}
+
+	client := http.Client{Timeout: probe.timeout}
+
+	resp, err := client.Get("http://" + target + probe.path)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		log.Printf("health check: %s%s: status %s", target, probe.path, resp.Status)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,148 @@
+package k8s
+
+import (
+	"log"
+	"regexp"
+
+	gatewayv1 "github.com/mcluseau/kingress/apis/gateway/v1"
+	"github.com/mcluseau/kingress/config"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// httpRouteRules is protected by config.Lock/Unlock, the same as
+// ingressRules and ingressRouteRules: it's written by httpRouteHandler and
+// read by newConfig, both already inside that lock.
+var httpRouteRules = map[string][]httpRouteRule{}
+
+// httpRouteBackendRef is one of an HTTPRouteRule's weighted backendRefs,
+// kept unresolved (service/port, not yet endpoints) until newConfig runs,
+// the same way IngressRoute's routeRule holds a Service/Port pair.
+type httpRouteBackendRef struct {
+	Service string
+	Port    intstr.IntOrString
+	Weight  int32
+}
+
+// httpRouteRule is an HTTPRoute rule resolved to one concrete host: its
+// path match and the weighted backends traffic should be split across.
+type httpRouteRule struct {
+	Host   string
+	Prefix string
+	// Match carries an anchored PathRegexp for "Exact" path matches;
+	// nil for "PathPrefix" ones, where Prefix's plain prefix match suffices.
+	Match *config.RouteMatch
+	Refs  []httpRouteBackendRef
+}
+
+type httpRouteHandler struct{}
+
+func (h httpRouteHandler) OnAdd(obj any) {
+	h.update(obj.(*gatewayv1.HTTPRoute))
+}
+
+func (h httpRouteHandler) OnUpdate(_, newObj any) {
+	h.update(newObj.(*gatewayv1.HTTPRoute))
+}
+
+func (h httpRouteHandler) OnDelete(obj any) {
+	h.delete(obj.(*gatewayv1.HTTPRoute))
+}
+
+func (h httpRouteHandler) update(route *gatewayv1.HTTPRoute) {
+	ref := k8sRef(route)
+
+	parents := parentGateways(route.Spec.ParentRefs, route.Namespace)
+	if len(parents) == 0 {
+		// no parent Gateway we own accepted this route (yet)
+		h.delete(route)
+		return
+	}
+
+	hostnames := route.Spec.Hostnames
+	if len(hostnames) == 0 {
+		for _, gw := range parents {
+			hostnames = append(hostnames, gatewayListenerHostnames(gw)...)
+		}
+	}
+
+	if len(hostnames) == 0 {
+		log.Printf("warning: httproute %s: no hostnames (spec.hostnames or a parent listener hostname required)", ref)
+		h.delete(route)
+		return
+	}
+
+	rules := make([]httpRouteRule, 0, len(route.Spec.Rules)*len(hostnames))
+
+	for _, rule := range route.Spec.Rules {
+		refs := make([]httpRouteBackendRef, 0, len(rule.BackendRefs))
+		for _, br := range rule.BackendRefs {
+			ns := route.Namespace
+			if br.Namespace != nil && *br.Namespace != "" {
+				ns = *br.Namespace
+			}
+
+			weight := int32(1)
+			if br.Weight != nil {
+				weight = *br.Weight
+			}
+
+			refs = append(refs, httpRouteBackendRef{
+				Service: ns + "/" + br.Name,
+				Port:    intstr.FromInt(int(br.Port)),
+				Weight:  weight,
+			})
+		}
+
+		if len(refs) == 0 {
+			continue
+		}
+
+		matches := rule.Matches
+		if len(matches) == 0 {
+			matches = []gatewayv1.HTTPRouteMatch{{}}
+		}
+
+		for _, m := range matches {
+			prefix := "/"
+			var match *config.RouteMatch
+
+			if m.Path != nil && m.Path.Value != "" {
+				prefix = m.Path.Value
+
+				if m.Path.Type == "Exact" {
+					re, err := regexp.Compile("^" + regexp.QuoteMeta(prefix) + "$")
+					if err != nil {
+						log.Printf("warning: httproute %s: bad exact path %q: %s", ref, prefix, err)
+						continue
+					}
+					match = &config.RouteMatch{PathRegexp: re}
+				}
+			}
+
+			for _, host := range hostnames {
+				rules = append(rules, httpRouteRule{
+					Host:   host,
+					Prefix: prefix,
+					Match:  match,
+					Refs:   refs,
+				})
+			}
+		}
+	}
+
+	config.Lock()
+	defer config.Unlock()
+
+	httpRouteRules[ref] = rules
+
+	config.NotifyChanged()
+}
+
+func (_ httpRouteHandler) delete(route *gatewayv1.HTTPRoute) {
+	config.Lock()
+	defer config.Unlock()
+
+	delete(httpRouteRules, k8sRef(route))
+
+	config.NotifyChanged()
+}
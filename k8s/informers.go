@@ -0,0 +1,277 @@
+package k8s
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/mcluseau/kingress/config"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/informers"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	discoverylisters "k8s.io/client-go/listers/discovery/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// serviceLister and endpointSliceLister replace the hand-rolled, per-event
+// maps servicesHandler/endpointsHandler used to maintain themselves:
+// findTargetPort/findEndpoints now read straight from the informer cache.
+var (
+	serviceLister       corelisters.ServiceLister
+	endpointSliceLister discoverylisters.EndpointSliceLister
+)
+
+// startServiceAndEndpointInformers wires up the Services and EndpointSlice
+// informers from factory. Their event handlers only call config.NotifyChanged
+// when the derived port/endpoint data actually changed, so a resync (which
+// re-delivers every object unchanged) doesn't trigger a config rebuild.
+func startServiceAndEndpointInformers(factory informers.SharedInformerFactory) {
+	svcInformer := factory.Core().V1().Services()
+	serviceLister = svcInformer.Lister()
+	svcInformer.Informer().AddEventHandler(servicesHandler{})
+
+	epsInformer := factory.Discovery().V1().EndpointSlices()
+	endpointSliceLister = epsInformer.Lister()
+	epsInformer.Informer().AddEventHandler(endpointSlicesHandler{})
+}
+
+// servicesHandler diffs a Service's port list against what was last seen
+// for it (see signatures) and only triggers a config rebuild when it
+// actually changed.
+type servicesHandler struct{}
+
+func (h servicesHandler) OnAdd(obj interface{})          { h.maybeNotify(obj) }
+func (h servicesHandler) OnUpdate(_, newObj interface{}) { h.maybeNotify(newObj) }
+
+func (servicesHandler) OnDelete(obj interface{}) {
+	if svc, ok := unwrapTombstone(obj).(*corev1.Service); ok {
+		forgetSignature("svc/" + k8sRef(svc))
+	}
+	config.NotifyChanged()
+}
+
+func (servicesHandler) maybeNotify(obj interface{}) {
+	svc, ok := unwrapTombstone(obj).(*corev1.Service)
+	if !ok {
+		return
+	}
+
+	if changedSignature("svc/"+k8sRef(svc), servicePortsSignature(svc)) {
+		config.NotifyChanged()
+	}
+}
+
+// endpointSlicesHandler does the same for EndpointSlices.
+type endpointSlicesHandler struct{}
+
+func (h endpointSlicesHandler) OnAdd(obj interface{})          { h.maybeNotify(obj) }
+func (h endpointSlicesHandler) OnUpdate(_, newObj interface{}) { h.maybeNotify(newObj) }
+
+func (endpointSlicesHandler) OnDelete(obj interface{}) {
+	if eps, ok := unwrapTombstone(obj).(*discoveryv1.EndpointSlice); ok {
+		forgetSignature("eps/" + k8sRef(eps))
+	}
+	config.NotifyChanged()
+}
+
+func (endpointSlicesHandler) maybeNotify(obj interface{}) {
+	eps, ok := unwrapTombstone(obj).(*discoveryv1.EndpointSlice)
+	if !ok {
+		return
+	}
+
+	if changedSignature("eps/"+k8sRef(eps), endpointSliceSignature(eps)) {
+		config.NotifyChanged()
+	}
+}
+
+// unwrapTombstone returns obj.Obj when client-go hands us a
+// DeletedFinalStateUnknown (a delete event it missed and is replaying from
+// its last known state), obj unchanged otherwise.
+func unwrapTombstone(obj interface{}) interface{} {
+	if tomb, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		return tomb.Obj
+	}
+	return obj
+}
+
+var (
+	signaturesMu sync.Mutex
+	signatures   = map[string]string{}
+)
+
+// changedSignature reports whether sig differs from the last one recorded
+// for key, recording sig either way. Guarded by a mutex since the Services
+// and EndpointSlices informers each deliver events from their own goroutine.
+func changedSignature(key, sig string) bool {
+	signaturesMu.Lock()
+	defer signaturesMu.Unlock()
+
+	changed := signatures[key] != sig
+	signatures[key] = sig
+	return changed
+}
+
+func forgetSignature(key string) {
+	signaturesMu.Lock()
+	defer signaturesMu.Unlock()
+
+	delete(signatures, key)
+}
+
+func servicePortsSignature(svc *corev1.Service) string {
+	parts := make([]string, 0, len(svc.Spec.Ports))
+	for _, p := range svc.Spec.Ports {
+		parts = append(parts, fmt.Sprintf("%s:%d:%s", p.Name, p.Port, p.TargetPort.String()))
+	}
+	sort.Strings(parts)
+
+	return hashOf(parts)
+}
+
+func endpointSliceSignature(eps *discoveryv1.EndpointSlice) string {
+	parts := make([]string, 0, len(eps.Endpoints)*2)
+
+	for _, ep := range eps.Endpoints {
+		ready := ep.Conditions.Ready == nil || *ep.Conditions.Ready
+
+		for _, addr := range ep.Addresses {
+			for _, port := range eps.Ports {
+				portNum := int32(0)
+				if port.Port != nil {
+					portNum = *port.Port
+				}
+
+				name := ""
+				if port.Name != nil {
+					name = *port.Name
+				}
+
+				parts = append(parts, fmt.Sprintf("%s:%d:%s:%v", addr, portNum, name, ready))
+			}
+		}
+	}
+	sort.Strings(parts)
+
+	return hashOf(parts)
+}
+
+func hashOf(parts []string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// findTargetPort resolves a Service's named/numeric port to its target
+// port, reading straight from serviceLister. serviceLister is nil on a
+// non-leader replica when -leader-election is set (see runElected), since
+// that replica never starts the Services/EndpointSlices informers; callers
+// get a "not found" rather than a nil-pointer panic.
+func findTargetPort(svcRef string, port intstr.IntOrString) (intstr.IntOrString, bool) {
+	if serviceLister == nil {
+		return intstr.FromString(""), false
+	}
+
+	ns, name, ok := splitRef(svcRef)
+	if !ok {
+		return intstr.FromString(""), false
+	}
+
+	svc, err := serviceLister.Services(ns).Get(name)
+	if err != nil {
+		return intstr.FromString(""), false
+	}
+
+	for _, svcPort := range svc.Spec.Ports {
+		switch port.Type {
+		case intstr.Int:
+			if svcPort.Port == port.IntVal {
+				return svcPort.TargetPort, true
+			}
+		case intstr.String:
+			if svcPort.Name == port.StrVal {
+				return svcPort.TargetPort, true
+			}
+		}
+	}
+
+	return intstr.FromString(""), false
+}
+
+// findEndpoints resolves a Service's ready endpoint addresses for the given
+// (already resolved, see findTargetPort) target port, across every
+// EndpointSlice for that service -- not just one object, so a service with
+// thousands of endpoints spread across many slices is enumerated fully
+// instead of hitting the single-Endpoints-object scaling wall.
+func findEndpoints(svcRef string, port intstr.IntOrString) []string {
+	if endpointSliceLister == nil {
+		return nil
+	}
+
+	ns, name, ok := splitRef(svcRef)
+	if !ok {
+		return nil
+	}
+
+	slices, err := endpointSliceLister.EndpointSlices(ns).List(labels.SelectorFromSet(labels.Set{
+		discoveryv1.LabelServiceName: name,
+	}))
+	if err != nil {
+		return nil
+	}
+
+	targets := make([]string, 0)
+
+	for _, eps := range slices {
+		for _, epsPort := range eps.Ports {
+			if !endpointPortMatches(epsPort, port) {
+				continue
+			}
+
+			portNum := int32(0)
+			if epsPort.Port != nil {
+				portNum = *epsPort.Port
+			}
+
+			for _, ep := range eps.Endpoints {
+				if ep.Conditions.Ready != nil && !*ep.Conditions.Ready {
+					continue
+				}
+
+				for _, addr := range ep.Addresses {
+					targets = append(targets, fmt.Sprintf("%s:%d", addr, portNum))
+				}
+			}
+		}
+	}
+
+	return targets
+}
+
+func endpointPortMatches(epsPort discoveryv1.EndpointPort, port intstr.IntOrString) bool {
+	switch port.Type {
+	case intstr.Int:
+		return epsPort.Port != nil && *epsPort.Port == port.IntVal
+	case intstr.String:
+		return epsPort.Name != nil && *epsPort.Name == port.StrVal
+	}
+	return false
+}
+
+func splitRef(ref string) (namespace, name string, ok bool) {
+	for i := len(ref) - 1; i >= 0; i-- {
+		if ref[i] == '/' {
+			return ref[:i], ref[i+1:], true
+		}
+	}
+	return "", "", false
+}
@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"flag"
 	"log"
 	"net"
 	"strings"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -17,7 +19,26 @@ import (
 	"github.com/mcluseau/kingress/config"
 )
 
+const (
+	// ingressClassAnnotation is the legacy, pre-IngressClass way of
+	// selecting a controller; still honored for backward compatibility.
+	ingressClassAnnotation = "kubernetes.io/ingress.class"
+
+	// defaultIngressClassAnnotation marks the IngressClass used for
+	// Ingresses that set neither the legacy annotation nor
+	// spec.ingressClassName.
+	defaultIngressClassAnnotation = "ingressclass.kubernetes.io/is-default-class"
+
+	// acmeAnnotation opts an ingress' TLS hosts into automatic ACME
+	// certificate issuance (see ACMEHosts) instead of requiring their
+	// secretName to be pre-populated.
+	acmeAnnotation = "kingress.mcluseau.github.io/acme"
+)
+
 var (
+	ingressClassName       = flag.String("ingress-class", "kingress", "IngressClass name (and legacy kubernetes.io/ingress.class value) this instance manages Ingresses for")
+	ingressClassController = flag.String("ingress-class-controller", "kingress.mcluseau.github.io/ingress-controller", "controller an IngressClass named -ingress-class must declare to be honored")
+
 	ingressRules   = map[string][]ingressRule{}
 	ingressSecrets = map[string][]ingressTLS{}
 )
@@ -33,15 +54,22 @@ type ingressRule struct {
 type ingressTLS struct {
 	Host      string
 	SecretRef string
+	ACME      bool
+}
+
+// ACMEHost identifies the Kubernetes Secret an automatically-issued ACME
+// certificate for a host should be persisted into.
+type ACMEHost struct {
+	Namespace string
+	Name      string
 }
 
 type ingressHandler struct {
 	k8s     *kubernetes.Clientset
 	LBHosts []string
-	Hosts   []string
 }
 
-func (h ingressHandler) OnAdd(obj any, isInInitialList bool) {
+func (h ingressHandler) OnAdd(obj any) {
 	h.update(obj.(*netv1.Ingress))
 }
 
@@ -54,6 +82,13 @@ func (h ingressHandler) OnDelete(obj any) {
 }
 
 func (h ingressHandler) update(ing *netv1.Ingress) {
+	if !ownsIngress(ing) {
+		// not ours (anymore): drop any rules we might have kept from a
+		// previous version of this ingress, and don't touch its status
+		h.delete(ing)
+		return
+	}
+
 	ref := k8sRef(ing)
 
 	// parse ingress options
@@ -114,6 +149,8 @@ func (h ingressHandler) update(ing *netv1.Ingress) {
 	}
 
 	// Collect host->secret associations
+	acme := ing.Annotations[acmeAnnotation] == "true"
+
 	secrets := make([]ingressTLS, 0)
 	for _, tls := range ing.Spec.TLS {
 		if tls.SecretName == "" {
@@ -126,6 +163,7 @@ func (h ingressHandler) update(ing *netv1.Ingress) {
 			secrets = append(secrets, ingressTLS{
 				Host:      host,
 				SecretRef: secretRef,
+				ACME:      acme,
 			})
 		}
 	}
@@ -136,13 +174,13 @@ func (h ingressHandler) update(ing *netv1.Ingress) {
 	ingressRules[ref] = rules
 	ingressSecrets[ref] = secrets
 
-	config.NotifyChanged(newConfig)
+	config.NotifyChanged()
 
 	// also check & update the status as needed
-	lb := netv1.IngressLoadBalancerStatus{}
+	lb := corev1.LoadBalancerStatus{}
 
 	for _, host := range h.LBHosts {
-		lbi := netv1.IngressLoadBalancerIngress{}
+		lbi := corev1.LoadBalancerIngress{}
 
 		if net.ParseIP(host) != nil {
 			lbi.IP = host
@@ -180,5 +218,75 @@ func (_ ingressHandler) delete(ing *netv1.Ingress) {
 	delete(ingressRules, ref)
 	delete(ingressSecrets, ref)
 
-	config.NotifyChanged(newConfig)
+	config.NotifyChanged()
+}
+
+// ownsIngress reports whether this instance should manage ing: through the
+// legacy kubernetes.io/ingress.class annotation, ing's spec.ingressClassName,
+// or (if neither is set) a default IngressClass, in that order of
+// precedence, matching how Kubernetes' own ingress controllers resolve it.
+func ownsIngress(ing *netv1.Ingress) bool {
+	if class, ok := ing.Annotations[ingressClassAnnotation]; ok {
+		return class == *ingressClassName
+	}
+
+	if ing.Spec.IngressClassName != nil {
+		return matchesIngressClass(*ing.Spec.IngressClassName)
+	}
+
+	return hasMatchingDefaultIngressClass()
+}
+
+// matchesIngressClass reports whether name is the IngressClass this
+// instance watches for, and that class resolves to -ingress-class-controller.
+func matchesIngressClass(name string) bool {
+	if name != *ingressClassName {
+		return false
+	}
+
+	ingressClassesMu.Lock()
+	ic, ok := ingressClasses[name]
+	ingressClassesMu.Unlock()
+
+	return ok && ic.Spec.Controller == *ingressClassController
+}
+
+func hasMatchingDefaultIngressClass() bool {
+	ingressClassesMu.Lock()
+	defer ingressClassesMu.Unlock()
+
+	for _, ic := range ingressClasses {
+		if ic.Annotations[defaultIngressClassAnnotation] == "true" && ic.Spec.Controller == *ingressClassController {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ACMEHosts returns, for every host opted into automatic ACME issuance (via
+// the kingress.mcluseau.github.io/acme: "true" ingress annotation), the
+// Secret its certificate should be persisted into.
+func ACMEHosts() map[string]ACMEHost {
+	config.Lock()
+	defer config.Unlock()
+
+	hosts := map[string]ACMEHost{}
+
+	for _, tlsEntries := range ingressSecrets {
+		for _, e := range tlsEntries {
+			if !e.ACME {
+				continue
+			}
+
+			ns, name, ok := strings.Cut(e.SecretRef, "/")
+			if !ok {
+				continue
+			}
+
+			hosts[e.Host] = ACMEHost{Namespace: ns, Name: name}
+		}
+	}
+
+	return hosts
 }
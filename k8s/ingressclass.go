@@ -0,0 +1,42 @@
+package k8s
+
+import (
+	"sync"
+
+	netv1 "k8s.io/api/networking/v1"
+
+	"github.com/mcluseau/kingress/config"
+)
+
+var (
+	ingressClasses   = map[string]*netv1.IngressClass{}
+	ingressClassesMu sync.Mutex
+)
+
+type ingressClassHandler struct{}
+
+func (h ingressClassHandler) OnAdd(obj any) {
+	h.update(obj.(*netv1.IngressClass))
+}
+
+func (h ingressClassHandler) OnUpdate(_, newObj any) {
+	h.update(newObj.(*netv1.IngressClass))
+}
+
+func (ingressClassHandler) OnDelete(obj any) {
+	ic := obj.(*netv1.IngressClass)
+
+	ingressClassesMu.Lock()
+	delete(ingressClasses, ic.Name)
+	ingressClassesMu.Unlock()
+
+	config.NotifyChanged()
+}
+
+func (ingressClassHandler) update(ic *netv1.IngressClass) {
+	ingressClassesMu.Lock()
+	ingressClasses[ic.Name] = ic
+	ingressClassesMu.Unlock()
+
+	config.NotifyChanged()
+}
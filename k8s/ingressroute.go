@@ -0,0 +1,115 @@
+package k8s
+
+import (
+	"log"
+	"regexp"
+
+	kingressv1 "github.com/mcluseau/kingress/apis/kingress/v1"
+	"github.com/mcluseau/kingress/config"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+var ingressRouteRules = map[string][]routeRule{}
+
+// routeRule is a Route resolved to the form config.go's newConfig needs: a
+// target service/port plus the RouteMatch predicates/actions the plain
+// ingressRule has no room for.
+type routeRule struct {
+	Host    string
+	Prefix  string
+	Match   *config.RouteMatch
+	Service string
+	Port    intstr.IntOrString
+}
+
+type ingressRouteHandler struct{}
+
+func (h ingressRouteHandler) OnAdd(obj interface{}) {
+	h.update(obj.(*kingressv1.IngressRoute))
+}
+
+func (h ingressRouteHandler) OnUpdate(oldObj, newObj interface{}) {
+	h.update(newObj.(*kingressv1.IngressRoute))
+}
+
+func (h ingressRouteHandler) OnDelete(obj interface{}) {
+	h.delete(obj.(*kingressv1.IngressRoute))
+}
+
+func (h ingressRouteHandler) update(ir *kingressv1.IngressRoute) {
+	ref := k8sRef(ir)
+
+	rules := make([]routeRule, 0, len(ir.Spec.Routes))
+
+routesLoop:
+	for _, route := range ir.Spec.Routes {
+		m := &config.RouteMatch{
+			Method:      route.Method,
+			StripPrefix: route.Actions.StripPrefix,
+			AddPrefix:   route.Actions.AddPrefix,
+		}
+
+		if route.HostRegexp != "" {
+			re, err := regexp.Compile(route.HostRegexp)
+			if err != nil {
+				log.Printf("warning: ingressroute %s: bad hostRegexp %q: %s", ref, route.HostRegexp, err)
+				continue
+			}
+			m.HostRegexp = re
+		}
+
+		if route.PathRegexp != "" {
+			re, err := regexp.Compile(route.PathRegexp)
+			if err != nil {
+				log.Printf("warning: ingressroute %s: bad pathRegexp %q: %s", ref, route.PathRegexp, err)
+				continue
+			}
+			m.PathRegexp = re
+		}
+
+		for _, hm := range route.Headers {
+			re, err := regexp.Compile(hm.Regexp)
+			if err != nil {
+				log.Printf("warning: ingressroute %s: bad header regexp %q for %s: %s", ref, hm.Regexp, hm.Name, err)
+				continue routesLoop
+			}
+			m.Headers = append(m.Headers, config.HeaderMatch{Name: hm.Name, Regexp: re})
+		}
+
+		if rpr := route.Actions.ReplacePathRegex; rpr != nil {
+			re, err := regexp.Compile(rpr.Regexp)
+			if err != nil {
+				log.Printf("warning: ingressroute %s: bad replacePathRegex %q: %s", ref, rpr.Regexp, err)
+				continue
+			}
+			m.ReplaceRegexp = re
+			m.ReplaceWith = rpr.Replacement
+		}
+
+		applyMiddlewares(m, ir.Namespace, route.Middlewares)
+
+		rules = append(rules, routeRule{
+			Host:    route.Host,
+			Prefix:  route.PathPrefix,
+			Match:   m,
+			Service: ir.Namespace + "/" + route.Service,
+			Port:    intstr.Parse(route.Port),
+		})
+	}
+
+	config.Lock()
+	defer config.Unlock()
+
+	ingressRouteRules[ref] = rules
+
+	config.NotifyChanged()
+}
+
+func (h ingressRouteHandler) delete(ir *kingressv1.IngressRoute) {
+	config.Lock()
+	defer config.Unlock()
+
+	delete(ingressRouteRules, k8sRef(ir))
+
+	config.NotifyChanged()
+}
@@ -3,15 +3,19 @@ package k8s
 import (
 	"flag"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
+	gatewayv1 "github.com/mcluseau/kingress/apis/gateway/v1"
+	kingressv1 "github.com/mcluseau/kingress/apis/kingress/v1"
 	"github.com/mcluseau/kingress/kubeclient"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/tools/cache"
 )
 
@@ -23,22 +27,92 @@ var (
 	namespace    = flag.String("namespace", metav1.NamespaceAll, "Namespace (defaults to all)")
 	selector     = flag.String("selector", "", "Ingress selector")
 	resyncPeriod = flag.Duration("resync-period", 10*time.Minute, "Period between full resyncs with Kubernetes")
+
+	providers = flag.String("providers", "ingress", "Comma-separated configuration sources to watch: ingress, gateway")
 )
 
+// providerEnabled reports whether name was listed in -providers.
+func providerEnabled(name string) bool {
+	for _, p := range strings.Split(*providers, ",") {
+		if strings.TrimSpace(p) == name {
+			return true
+		}
+	}
+	return false
+}
+
 func Start(hosts []string) {
 	stopCh = make(chan struct{}, 1)
 
 	c := kubeclient.Client()
 
-	// watch ingresses
-	watchK8s(c.NetworkingV1().RESTClient(), "ingresses", *selector, &netv1.Ingress{}, ingressHandler{c, hosts})
+	if providerEnabled("ingress") {
+		// watch ingress classes, so ingressHandler can resolve ownership
+		watchK8s(c.NetworkingV1().RESTClient(), "ingressclasses", "", &netv1.IngressClass{}, ingressClassHandler{})
+	}
+
+	// Services, EndpointSlices and (when enabled) Ingresses are watched
+	// through a SharedInformerFactory with typed listers instead of
+	// watchK8s's raw ListWatch+NewInformer, so findTargetPort/findEndpoints
+	// can read the cache directly (see informers.go) instead of this
+	// package maintaining its own copy of the data. When -leader-election
+	// is set, only the elected replica runs this watch (see runElected).
+	factory := informers.NewSharedInformerFactoryWithOptions(c, *resyncPeriod, informers.WithNamespace(*namespace))
+
+	startWatchedResources := func(stop <-chan struct{}) {
+		startServiceAndEndpointInformers(factory)
+
+		if providerEnabled("ingress") {
+			factory.Networking().V1().Ingresses().Informer().AddEventHandler(ingressHandler{k8s: c, LBHosts: hosts})
+		}
+
+		factory.Start(stop)
+		factory.WaitForCacheSync(stop)
+
+		log.Print("kubernetes: watching services, endpointslices, ingresses")
+		<-stop
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
 
-	// watch services & endpoints
-	watchK8s(c.CoreV1().RESTClient(), "services", "", &corev1.Service{}, servicesHandler{})
-	watchK8s(c.CoreV1().RESTClient(), "endpoints", "", &corev1.Endpoints{}, endpointsHandler{})
+		if *leaderElection {
+			runElected(c, stopCh, startWatchedResources)
+		} else {
+			startWatchedResources(stopCh)
+		}
+	}()
 
 	// watch secrets
 	watchK8s(c.CoreV1().RESTClient(), "secrets", "", &corev1.Secret{}, secretsHandler{})
+
+	// watch our IngressRoute/Middleware CRDs, if reachable
+	if crdClient, err := kubeclient.KingressV1Client(); err != nil {
+		log.Print("kubernetes: kingress CRDs not available: ", err)
+	} else {
+		watchK8s(crdClient, "ingressroutes", "", &kingressv1.IngressRoute{}, ingressRouteHandler{})
+		watchK8s(crdClient, "middlewares", "", &kingressv1.Middleware{}, middlewareHandler{})
+	}
+
+	if providerEnabled("gateway") {
+		// watch the Gateway API CRDs, if reachable
+		if gwClient, err := kubeclient.GatewayV1Client(); err != nil {
+			log.Print("kubernetes: gateway API CRDs not available: ", err)
+		} else {
+			watchK8s(gwClient, "gatewayclasses", "", &gatewayv1.GatewayClass{}, gatewayClassHandler{})
+			watchK8s(gwClient, "gateways", "", &gatewayv1.Gateway{}, gatewayHandler{Client: gwClient, LBHosts: hosts})
+			watchK8s(gwClient, "httproutes", "", &gatewayv1.HTTPRoute{}, httpRouteHandler{})
+			watchK8s(gwClient, "tlsroutes", "", &gatewayv1.TLSRoute{}, tlsRouteHandler{})
+		}
+	}
+
+	// actively probe endpoint targets
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		startHealthChecker()
+	}()
 }
 
 func Stop() {
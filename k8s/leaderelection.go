@@ -0,0 +1,79 @@
+package k8s
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+var (
+	leaderElection          = flag.Bool("leader-election", false, "Elect a single leader among replicas to watch Services/EndpointSlices/Ingresses, instead of every replica watching independently")
+	leaderElectionNamespace = flag.String("leader-election-namespace", "", "Namespace of the Lease object used for -leader-election (defaults to -namespace, or \"default\" if that's unset/all-namespaces)")
+	leaderElectionID        = flag.String("leader-election-id", "kingress-leader", "Name of the Lease object used for -leader-election")
+)
+
+// runElected calls run (with a context cancelled when leadership is lost or
+// stop closes) once this replica is elected leader, retrying the election
+// forever so a new leader takes over if the current one disappears.
+//
+// Only the elected replica watches Services/EndpointSlices/Ingresses and
+// rebuilds Config from them; a non-leader replica keeps serving whatever
+// Config it last had (empty until it has itself been elected at least
+// once). This trades per-replica freshness for fewer duplicate watches
+// against the API server, so it's only appropriate where that's an
+// acceptable tradeoff -- e.g. a small, fairly stable set of replicas,
+// rather than one that scales up and down often.
+func runElected(c *kubernetes.Clientset, stop <-chan struct{}, run func(stop <-chan struct{})) {
+	ns := *leaderElectionNamespace
+	if ns == "" {
+		ns = *namespace
+	}
+	if ns == "" || ns == metav1.NamespaceAll {
+		ns = "default"
+	}
+
+	id, err := os.Hostname()
+	if err != nil {
+		id = "kingress-unknown"
+	}
+
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{Namespace: ns, Name: *leaderElectionID},
+		Client:    c.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: id,
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	for ctx.Err() == nil {
+		leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+			Lock:            lock,
+			ReleaseOnCancel: true,
+			LeaseDuration:   15 * time.Second,
+			RenewDeadline:   10 * time.Second,
+			RetryPeriod:     2 * time.Second,
+			Callbacks: leaderelection.LeaderCallbacks{
+				OnStartedLeading: func(leCtx context.Context) {
+					log.Print("kubernetes: elected leader, starting Services/EndpointSlices/Ingresses watch")
+					run(leCtx.Done())
+				},
+				OnStoppedLeading: func() {
+					log.Print("kubernetes: lost leadership")
+				},
+			},
+		})
+	}
+}
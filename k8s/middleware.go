@@ -0,0 +1,88 @@
+package k8s
+
+import (
+	"net"
+	"sync"
+
+	kingressv1 "github.com/mcluseau/kingress/apis/kingress/v1"
+	"github.com/mcluseau/kingress/config"
+)
+
+var (
+	middlewaresMu sync.Mutex
+	middlewares   = map[string]*kingressv1.Middleware{}
+)
+
+type middlewareHandler struct{}
+
+func (h middlewareHandler) OnAdd(obj interface{}) {
+	h.update(obj.(*kingressv1.Middleware))
+}
+
+func (h middlewareHandler) OnUpdate(oldObj, newObj interface{}) {
+	h.update(newObj.(*kingressv1.Middleware))
+}
+
+func (h middlewareHandler) OnDelete(obj interface{}) {
+	mw := obj.(*kingressv1.Middleware)
+
+	middlewaresMu.Lock()
+	delete(middlewares, k8sRef(mw))
+	middlewaresMu.Unlock()
+
+	config.NotifyChanged()
+}
+
+func (h middlewareHandler) update(mw *kingressv1.Middleware) {
+	middlewaresMu.Lock()
+	middlewares[k8sRef(mw)] = mw
+	middlewaresMu.Unlock()
+
+	config.NotifyChanged()
+}
+
+// applyMiddlewares resolves each named Middleware (looked up in namespace)
+// onto m, the last matching kind of each type taking effect.
+func applyMiddlewares(m *config.RouteMatch, namespace string, names []string) {
+	middlewaresMu.Lock()
+	defer middlewaresMu.Unlock()
+
+	for _, name := range names {
+		mw, ok := middlewares[namespace+"/"+name]
+		if !ok {
+			continue
+		}
+
+		spec := mw.Spec
+
+		if spec.BasicAuth != nil {
+			ref := namespace + "/" + spec.BasicAuth.SecretRef
+			if users, ok := basicAuthUsers[ref]; ok {
+				m.BasicAuth = &config.BasicAuth{
+					Realm: spec.BasicAuth.Realm,
+					Users: users,
+				}
+			}
+		}
+
+		if spec.IPAllowList != nil {
+			if nets, ok := parseCIDRs(spec.IPAllowList.CIDRs); ok {
+				m.IPAllowList = nets
+			}
+		}
+	}
+}
+
+func parseCIDRs(values []string) (nets []*net.IPNet, ok bool) {
+	nets = make([]*net.IPNet, 0, len(values))
+
+	for _, v := range values {
+		_, ipnet, err := net.ParseCIDR(v)
+		if err != nil {
+			return nil, false
+		}
+		nets = append(nets, ipnet)
+	}
+
+	return nets, true
+}
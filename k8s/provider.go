@@ -0,0 +1,24 @@
+package k8s
+
+import "github.com/mcluseau/kingress/config"
+
+// providerName identifies this package's Kubernetes watchers in config's
+// deterministic provider merge order (see config.RegisterProvider).
+const providerName = "kubernetes"
+
+// Provider adapts this package's Kubernetes watchers to the generic
+// config.Provider interface, so main can run it alongside other
+// configuration sources such as a KV-store-backed provider.
+type Provider struct {
+	Hosts []string
+}
+
+func (Provider) Name() string { return providerName }
+
+func (Provider) Register() { config.RegisterProvider(providerName, newConfig) }
+
+func (p Provider) Run(stop <-chan struct{}) {
+	Start(p.Hosts)
+	<-stop
+	Stop()
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/tls"
 	"flag"
 	"log"
+	"strings"
 
 	core "k8s.io/api/core/v1"
 
@@ -14,12 +15,17 @@ var (
 	secretCertificate = map[string]*tls.Certificate{}
 	defaultCert       *tls.Certificate
 
+	// basicAuthUsers holds the parsed credentials of Opaque secrets
+	// referenced by a Middleware's basicAuth.secretRef (a "users" key with
+	// one "user:password" pair per line).
+	basicAuthUsers = map[string]map[string]string{}
+
 	tlsSecretName = flag.String("tls-secret", "default/kingress-default", "Default TLS secret (format: namespace/name)")
 )
 
 type secretsHandler struct{}
 
-func (h secretsHandler) OnAdd(obj interface{}, isInInitialList bool) {
+func (h secretsHandler) OnAdd(obj interface{}) {
 	h.update(obj.(*core.Secret))
 }
 func (h secretsHandler) OnUpdate(oldObj, newObj interface{}) {
@@ -30,38 +36,73 @@ func (h secretsHandler) OnDelete(obj interface{}) {
 }
 
 func (h secretsHandler) update(secret *core.Secret) {
-	if secret.Type != core.SecretTypeTLS {
-		h.delete(secret) // can secrets change type? I suppose not but better be safe
-		return
-	}
-
 	ref := k8sRef(secret)
 
-	cert, err := tls.X509KeyPair(secret.Data["tls.crt"], secret.Data["tls.key"])
+	switch secret.Type {
+	case core.SecretTypeTLS:
+		cert, err := tls.X509KeyPair(secret.Data["tls.crt"], secret.Data["tls.key"])
+		if err != nil {
+			log.Printf("error: tls secret %s is invalid: %v", ref, err)
+			h.delete(secret)
+			return
+		}
 
-	if err != nil {
-		log.Printf("error: tls secret %s is invalid: %v", ref, err)
-		h.delete(secret)
-		return
-	}
+		config.Lock()
+		defer config.Unlock()
 
-	config.Lock()
-	defer config.Unlock()
+		secretCertificate[ref] = &cert
+		delete(basicAuthUsers, ref)
+
+		if ref == *tlsSecretName {
+			defaultCert = &cert
+		}
+
+	case core.SecretTypeOpaque:
+		users := parseBasicAuthUsers(secret.Data["users"])
 
-	secretCertificate[ref] = &cert
+		config.Lock()
+		defer config.Unlock()
 
-	if ref == *tlsSecretName {
-		defaultCert = &cert
+		basicAuthUsers[ref] = users
+		delete(secretCertificate, ref)
+
+	default:
+		h.delete(secret) // not a secret type we use
+		return
 	}
 
-	config.NotifyChanged(newConfig)
+	config.NotifyChanged()
 }
 
 func (_ secretsHandler) delete(secret *core.Secret) {
 	config.Lock()
 	defer config.Unlock()
 
-	delete(secretCertificate, k8sRef(secret))
+	ref := k8sRef(secret)
+	delete(secretCertificate, ref)
+	delete(basicAuthUsers, ref)
+
+	config.NotifyChanged()
+}
+
+// parseBasicAuthUsers parses a "user:password" per line secret value, as
+// referenced by a Middleware's basicAuth.secretRef.
+func parseBasicAuthUsers(data []byte) map[string]string {
+	users := map[string]string{}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		user, pass, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+
+		users[user] = pass
+	}
 
-	config.NotifyChanged(newConfig)
+	return users
 }
@@ -0,0 +1,99 @@
+package k8s
+
+import (
+	"log"
+
+	gatewayv1 "github.com/mcluseau/kingress/apis/gateway/v1"
+	"github.com/mcluseau/kingress/config"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// tlsRouteRules holds TLSRoute-derived rules, protected by config.Lock
+// like httpRouteRules. TLSRoute has no path or header matching, only SNI
+// hostnames, so each resolves to a full-host, root-prefix httpRouteRule
+// and is merged into newConfig the same way.
+var tlsRouteRules = map[string][]httpRouteRule{}
+
+type tlsRouteHandler struct{}
+
+func (h tlsRouteHandler) OnAdd(obj any) {
+	h.update(obj.(*gatewayv1.TLSRoute))
+}
+
+func (h tlsRouteHandler) OnUpdate(_, newObj any) {
+	h.update(newObj.(*gatewayv1.TLSRoute))
+}
+
+func (h tlsRouteHandler) OnDelete(obj any) {
+	h.delete(obj.(*gatewayv1.TLSRoute))
+}
+
+func (h tlsRouteHandler) update(route *gatewayv1.TLSRoute) {
+	ref := k8sRef(route)
+
+	parents := parentGateways(route.Spec.ParentRefs, route.Namespace)
+	if len(parents) == 0 {
+		h.delete(route)
+		return
+	}
+
+	hostnames := route.Spec.Hostnames
+	if len(hostnames) == 0 {
+		for _, gw := range parents {
+			hostnames = append(hostnames, gatewayListenerHostnames(gw)...)
+		}
+	}
+
+	if len(hostnames) == 0 {
+		log.Printf("warning: tlsroute %s: no hostnames (spec.hostnames or a parent listener hostname required)", ref)
+		h.delete(route)
+		return
+	}
+
+	var refs []httpRouteBackendRef
+	for _, rule := range route.Spec.Rules {
+		for _, br := range rule.BackendRefs {
+			ns := route.Namespace
+			if br.Namespace != nil && *br.Namespace != "" {
+				ns = *br.Namespace
+			}
+
+			weight := int32(1)
+			if br.Weight != nil {
+				weight = *br.Weight
+			}
+
+			refs = append(refs, httpRouteBackendRef{
+				Service: ns + "/" + br.Name,
+				Port:    intstr.FromInt(int(br.Port)),
+				Weight:  weight,
+			})
+		}
+	}
+
+	if len(refs) == 0 {
+		h.delete(route)
+		return
+	}
+
+	rules := make([]httpRouteRule, 0, len(hostnames))
+	for _, host := range hostnames {
+		rules = append(rules, httpRouteRule{Host: host, Prefix: "/", Refs: refs})
+	}
+
+	config.Lock()
+	defer config.Unlock()
+
+	tlsRouteRules[ref] = rules
+
+	config.NotifyChanged()
+}
+
+func (_ tlsRouteHandler) delete(route *gatewayv1.TLSRoute) {
+	config.Lock()
+	defer config.Unlock()
+
+	delete(tlsRouteRules, k8sRef(route))
+
+	config.NotifyChanged()
+}
@@ -6,9 +6,14 @@ import (
 	"os"
 	"sync"
 
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/client-go/kubernetes"
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+
+	gatewayv1 "github.com/mcluseau/kingress/apis/gateway/v1"
+	kingressv1 "github.com/mcluseau/kingress/apis/kingress/v1"
 )
 
 var (
@@ -25,6 +30,53 @@ func Client() *kubernetes.Clientset {
 	return k
 }
 
+// KingressV1Client returns a REST client for the kingress.mcluseau.github.io/v1
+// CRDs (IngressRoute, Middleware). It shares connection settings with Client()
+// but talks to the CustomResourceDefinition API instead of the builtin one.
+func KingressV1Client() (restclient.Interface, error) {
+	once.Do(connect)
+
+	crdScheme := runtime.NewScheme()
+	if err := kingressv1.AddToScheme(crdScheme); err != nil {
+		return nil, err
+	}
+
+	cfg := *config
+	cfg.GroupVersion = &kingressv1.SchemeGroupVersion
+	cfg.APIPath = "/apis"
+	cfg.NegotiatedSerializer = serializer.NewCodecFactory(crdScheme).WithoutConversion()
+
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = restclient.DefaultKubernetesUserAgent()
+	}
+
+	return restclient.RESTClientFor(&cfg)
+}
+
+// GatewayV1Client returns a REST client for the gateway.networking.k8s.io/v1
+// CRDs (GatewayClass, Gateway, HTTPRoute, TLSRoute). It shares connection
+// settings with Client() but talks to the Gateway API instead of the
+// builtin one.
+func GatewayV1Client() (restclient.Interface, error) {
+	once.Do(connect)
+
+	crdScheme := runtime.NewScheme()
+	if err := gatewayv1.AddToScheme(crdScheme); err != nil {
+		return nil, err
+	}
+
+	cfg := *config
+	cfg.GroupVersion = &gatewayv1.SchemeGroupVersion
+	cfg.APIPath = "/apis"
+	cfg.NegotiatedSerializer = serializer.NewCodecFactory(crdScheme).WithoutConversion()
+
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = restclient.DefaultKubernetesUserAgent()
+	}
+
+	return restclient.RESTClientFor(&cfg)
+}
+
 func connect() {
 	// Use in-cluster config or provide options
 	var err error
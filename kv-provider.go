@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/mcluseau/kingress/config"
+	"github.com/mcluseau/kingress/kv"
+)
+
+var (
+	kvConsulAddr = flag.String("kv-consul-addr", "", "Consul agent address to read backends/certificates from (e.g. http://127.0.0.1:8500); empty disables it")
+	kvEtcdAddr   = flag.String("kv-etcd-addr", "", "etcd v3 gRPC-gateway address to read backends/certificates from (e.g. http://127.0.0.1:2379); empty disables it")
+	kvPrefix     = flag.String("kv-prefix", "kingress/", "Key prefix holding kingress' KV-backed configuration")
+)
+
+// startKVProviders runs, through run, every KV-backed config.Provider
+// enabled by flags. They run alongside the Kubernetes provider, which main
+// always starts: kingress still needs a Kubernetes client configuration it
+// can build (in-cluster, -kubeconfig or -master) and exits at startup
+// without one, even when all its backends come from Consul and/or etcd.
+func startKVProviders(run func(config.Provider)) {
+	if *kvConsulAddr != "" {
+		run(&kv.Provider{
+			ProviderName: "kv-consul",
+			Client:       kv.NewConsulClient(*kvConsulAddr),
+			Prefix:       *kvPrefix,
+		})
+	}
+
+	if *kvEtcdAddr != "" {
+		run(&kv.Provider{
+			ProviderName: "kv-etcd",
+			Client:       kv.NewEtcdClient(*kvEtcdAddr),
+			Prefix:       *kvPrefix,
+		})
+	}
+}
@@ -0,0 +1,17 @@
+// Package kv provides a config.Provider backed by a hierarchical
+// key/value store, run next to the Kubernetes provider.
+package kv
+
+// Client is the minimal interface a key/value store backend needs to
+// implement for Provider: listing everything under a prefix, and blocking
+// until something under that prefix changes.
+type Client interface {
+	// List returns every key (relative to the store root) under prefix,
+	// with its current value.
+	List(prefix string) (map[string]string, error)
+
+	// Watch blocks until stop is closed, sending on changed whenever it
+	// observes a change under prefix. Implementations usually loop over a
+	// long-poll/blocking query native to the store until stop is closed.
+	Watch(prefix string, stop <-chan struct{}, changed chan<- struct{})
+}
@@ -0,0 +1,103 @@
+package kv
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConsulClient implements Client against a Consul agent's HTTP KV API,
+// using blocking queries (see
+// https://developer.hashicorp.com/consul/api-docs/features/blocking) to
+// watch for changes without vendoring Consul's own client library.
+type ConsulClient struct {
+	// Addr is the Consul agent's HTTP address, e.g. "http://127.0.0.1:8500".
+	Addr string
+}
+
+func NewConsulClient(addr string) *ConsulClient {
+	return &ConsulClient{Addr: addr}
+}
+
+type consulKVEntry struct {
+	Key   string
+	Value string // base64-encoded
+}
+
+func (c *ConsulClient) List(prefix string) (map[string]string, error) {
+	entries, _, err := c.query(prefix, 0, 0)
+	return entries, err
+}
+
+func (c *ConsulClient) Watch(prefix string, stop <-chan struct{}, changed chan<- struct{}) {
+	var index uint64
+
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		_, newIndex, err := c.query(prefix, index, 5*time.Minute)
+		if err != nil {
+			time.Sleep(5 * time.Second)
+			continue
+		}
+
+		if newIndex != index {
+			index = newIndex
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// query performs a (possibly blocking) Consul KV recursive read, returning
+// the entries under prefix and the store's current X-Consul-Index.
+func (c *ConsulClient) query(prefix string, index uint64, wait time.Duration) (map[string]string, uint64, error) {
+	url := fmt.Sprintf("%s/v1/kv/%s?recurse=true", strings.TrimRight(c.Addr, "/"), prefix)
+	if index > 0 {
+		url += fmt.Sprintf("&index=%d&wait=%s", index, wait)
+	}
+
+	client := &http.Client{Timeout: wait + 30*time.Second}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	newIndex, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+
+	if resp.StatusCode == http.StatusNotFound {
+		return map[string]string{}, newIndex, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("consul: unexpected status %s", resp.Status)
+	}
+
+	var raw []consulKVEntry
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, 0, err
+	}
+
+	entries := make(map[string]string, len(raw))
+	for _, e := range raw {
+		value, err := base64.StdEncoding.DecodeString(e.Value)
+		if err != nil {
+			continue
+		}
+		entries[e.Key] = string(value)
+	}
+
+	return entries, newIndex, nil
+}
@@ -0,0 +1,156 @@
+package kv
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// EtcdClient implements Client against etcd v3's JSON/gRPC-gateway HTTP
+// API (see https://etcd.io/docs/v3.5/dev-guide/api_grpc_gateway/), so
+// kingress doesn't need to vendor etcd's own gRPC client.
+type EtcdClient struct {
+	// Addr is the etcd gRPC-gateway address, e.g. "http://127.0.0.1:2379".
+	Addr string
+}
+
+func NewEtcdClient(addr string) *EtcdClient {
+	return &EtcdClient{Addr: addr}
+}
+
+type etcdKV struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type etcdRangeResponse struct {
+	Kvs []etcdKV `json:"kvs"`
+}
+
+func (c *EtcdClient) List(prefix string) (map[string]string, error) {
+	body, _ := json.Marshal(map[string]string{
+		"key":       b64(prefix),
+		"range_end": b64(prefixRangeEnd(prefix)),
+	})
+
+	resp, err := http.Post(strings.TrimRight(c.Addr, "/")+"/v3/kv/range", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("etcd: unexpected status %s", resp.Status)
+	}
+
+	var rr etcdRangeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rr); err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]string, len(rr.Kvs))
+	for _, kv := range rr.Kvs {
+		key, err := base64.StdEncoding.DecodeString(kv.Key)
+		if err != nil {
+			continue
+		}
+
+		value, err := base64.StdEncoding.DecodeString(kv.Value)
+		if err != nil {
+			continue
+		}
+
+		entries[string(key)] = string(value)
+	}
+
+	return entries, nil
+}
+
+func (c *EtcdClient) Watch(prefix string, stop <-chan struct{}, changed chan<- struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		if err := c.watchOnce(prefix, stop, changed); err != nil {
+			time.Sleep(5 * time.Second)
+		}
+	}
+}
+
+// watchOnce opens etcd's streaming watch endpoint and relays one signal on
+// changed per non-empty event batch, until the stream ends or stop closes.
+func (c *EtcdClient) watchOnce(prefix string, stop <-chan struct{}, changed chan<- struct{}) error {
+	body, _ := json.Marshal(map[string]any{
+		"create_request": map[string]string{
+			"key":       b64(prefix),
+			"range_end": b64(prefixRangeEnd(prefix)),
+		},
+	})
+
+	req, err := http.NewRequest("POST", strings.TrimRight(c.Addr, "/")+"/v3/watch", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-stop:
+			resp.Body.Close()
+		case <-done:
+		}
+	}()
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var msg struct {
+			Result struct {
+				Events []json.RawMessage `json:"events"`
+			} `json:"result"`
+		}
+
+		if err := dec.Decode(&msg); err != nil {
+			return err
+		}
+
+		if len(msg.Result.Events) == 0 {
+			continue // the initial "created" message carries no events
+		}
+
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func b64(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+// prefixRangeEnd returns the etcd range_end that selects every key with the
+// given prefix: the prefix with its last byte incremented.
+func prefixRangeEnd(prefix string) string {
+	b := []byte(prefix)
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] < 0xff {
+			b[i]++
+			return string(b[:i+1])
+		}
+	}
+	return "" // prefix is all 0xff bytes: no upper bound
+}
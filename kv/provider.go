@@ -0,0 +1,333 @@
+package kv
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mcluseau/kingress/config"
+)
+
+// Provider is a config.Provider that builds its Snapshot from a
+// hierarchical key layout read through Client:
+//
+//	<prefix>backends/<host>/<path-prefix>/targets = ip:port,ip:port
+//	<prefix>backends/<host>/<path-prefix>/options/<annotation> = value
+//	<prefix>certs/<name>/tls.crt
+//	<prefix>certs/<name>/tls.key
+//
+// <path-prefix> may itself contain slashes; it's whatever sits between the
+// host and the trailing "targets"/"options/<annotation>" key. <annotation>
+// is any name known to config.Annotations (the same ones an Ingress can
+// set, e.g. "lb-policy" or "ssl-redirect").
+//
+// A backend's targets can also be named and shared across hosts, Traefik
+// KV-provider style, by pointing a frontend at it instead of listing
+// "targets" directly:
+//
+//	<prefix>frontends/<name>/host = example.com
+//	<prefix>frontends/<name>/path = /some/prefix   (optional, defaults to "/")
+//	<prefix>frontends/<name>/backend = <backend-name>
+//	<prefix>frontends/<name>/options/<annotation> = value
+//	<prefix>backends/<backend-name>/servers/<id>/url = ip:port
+//
+// <id> is only there to let a KV store hold several servers under the same
+// backend name; its value is never read. A host/path-prefix reached through
+// a frontend entry is equivalent to one reached directly under
+// "backends/<host>/<path-prefix>" -- both end up in the same Backends map.
+type Provider struct {
+	// ProviderName identifies this provider in config's deterministic
+	// merge order (see config.RegisterProvider), e.g. "kv-consul".
+	ProviderName string
+	Client       Client
+	Prefix       string
+
+	mu     sync.Mutex
+	latest config.Snapshot
+}
+
+func (p *Provider) Name() string { return p.ProviderName }
+
+func (p *Provider) Register() { config.RegisterProvider(p.ProviderName, p.snapshot) }
+
+func (p *Provider) Run(stop <-chan struct{}) {
+	changed := make(chan struct{}, 1)
+	go p.Client.Watch(p.Prefix, stop, changed)
+
+	p.reload()
+	config.NotifyChanged()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-changed:
+			p.reload()
+			config.NotifyChanged()
+		}
+	}
+}
+
+func (p *Provider) reload() {
+	entries, err := p.Client.List(p.Prefix)
+	if err != nil {
+		log.Print(p.ProviderName, ": list ", p.Prefix, ": ", err)
+		return
+	}
+
+	snap := buildSnapshot(p.Prefix, entries)
+
+	p.mu.Lock()
+	p.latest = snap
+	p.mu.Unlock()
+}
+
+func (p *Provider) snapshot() config.Snapshot {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.latest
+}
+
+type backendSpec struct {
+	targets []string
+	options config.BackendOptions
+}
+
+func buildSnapshot(prefix string, entries map[string]string) config.Snapshot {
+	backends := map[string]map[string]*backendSpec{} // host -> path prefix -> spec
+	namedServers := map[string][]string{}            // backend name -> server urls
+	frontends := map[string]*frontendSpec{}          // frontend name -> spec
+	certFiles := map[string]map[string]string{}      // name -> file -> contents
+	var errs []string
+
+	for key, value := range entries {
+		rel := strings.TrimPrefix(strings.TrimPrefix(key, prefix), "/")
+		parts := strings.Split(rel, "/")
+
+		switch {
+		case len(parts) == 5 && parts[0] == "backends" && parts[2] == "servers" && parts[4] == "url":
+			name := parts[1]
+			if v := strings.TrimSpace(value); v != "" {
+				namedServers[name] = append(namedServers[name], v)
+			}
+
+		case len(parts) >= 2 && parts[0] == "backends":
+			applyBackendKey(backends, parts[1], parts[2:], value, &errs)
+
+		case len(parts) >= 2 && parts[0] == "frontends":
+			applyFrontendKey(frontends, parts[1], parts[2:], value, &errs)
+
+		case len(parts) == 3 && parts[0] == "certs":
+			name, file := parts[1], parts[2]
+			m := certFiles[name]
+			if m == nil {
+				m = map[string]string{}
+				certFiles[name] = m
+			}
+			m[file] = value
+		}
+	}
+
+	for _, servers := range namedServers {
+		sort.Strings(servers)
+	}
+
+	// Sorted so that, like mergeSnapshots, a collision on the
+	// same host/path is resolved deterministically (first one wins) instead
+	// of depending on Go's randomized map iteration order.
+	frontendNames := make([]string, 0, len(frontends))
+	for name := range frontends {
+		frontendNames = append(frontendNames, name)
+	}
+	sort.Strings(frontendNames)
+
+	for _, name := range frontendNames {
+		fe := frontends[name]
+
+		if fe.host == "" {
+			errs = append(errs, fmt.Sprintf("kv: frontend %s: no host set", name))
+			continue
+		}
+
+		if fe.backend == "" {
+			errs = append(errs, fmt.Sprintf("kv: frontend %s: no backend set", name))
+			continue
+		}
+
+		targets := namedServers[fe.backend]
+		if len(targets) == 0 {
+			errs = append(errs, fmt.Sprintf("kv: frontend %s: backend %q has no servers", name, fe.backend))
+			continue
+		}
+
+		pathPrefix := fe.path
+		if pathPrefix == "" {
+			pathPrefix = "/"
+		}
+
+		byPrefix := backends[fe.host]
+		if byPrefix == nil {
+			byPrefix = map[string]*backendSpec{}
+			backends[fe.host] = byPrefix
+		}
+
+		if _, exists := byPrefix[pathPrefix]; exists {
+			errs = append(errs, fmt.Sprintf(
+				"warning: duplicate definition for host %s, path %s: ignoring frontend %s", fe.host, pathPrefix, name))
+			continue
+		}
+
+		byPrefix[pathPrefix] = &backendSpec{
+			targets: targets,
+			options: fe.options,
+		}
+	}
+
+	newBackends := config.Backends{}
+	for host, byPrefix := range backends {
+		for pathPrefix, spec := range byPrefix {
+			newBackends[host] = append(newBackends[host], &config.Backend{
+				IngressRef: "kv:" + host + pathPrefix,
+				Prefix:     pathPrefix,
+				Targets:    spec.targets,
+				Options:    spec.options,
+			})
+		}
+
+		// getBackend (http-handler.go) picks the first entry whose prefix
+		// matches, so longest-prefix-first order must be deterministic here
+		// too -- not left to the above maps' randomized iteration order
+		// (see k8s/config.go's equivalent backendsOrder sort).
+		hostBackends := newBackends[host]
+		sort.Slice(hostBackends, func(i, j int) bool {
+			return len(hostBackends[i].Prefix) > len(hostBackends[j].Prefix)
+		})
+	}
+
+	newCerts := config.Certificates{}
+	for name, files := range certFiles {
+		crt, key := files["tls.crt"], files["tls.key"]
+		if crt == "" || key == "" {
+			continue
+		}
+
+		cert, err := tls.X509KeyPair([]byte(crt), []byte(key))
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("kv: bad certificate %q: %v", name, err))
+			continue
+		}
+
+		newCerts[name] = &cert
+	}
+
+	return config.Snapshot{
+		HostBackends: newBackends,
+		HostCerts:    newCerts,
+		Errors:       errs,
+	}
+}
+
+// applyBackendKey folds one "backends/<host>/..." entry into backends,
+// recognizing the trailing "targets" or "options/<name>" key.
+func applyBackendKey(backends map[string]map[string]*backendSpec, host string, rest []string, value string, errs *[]string) {
+	if len(rest) == 0 {
+		return
+	}
+
+	last := rest[len(rest)-1]
+
+	var pathParts []string
+	isOption := len(rest) >= 2 && rest[len(rest)-2] == "options"
+
+	switch {
+	case isOption:
+		pathParts = rest[:len(rest)-2]
+	case last == "targets":
+		pathParts = rest[:len(rest)-1]
+	default:
+		return
+	}
+
+	pathPrefix := "/" + strings.Join(pathParts, "/")
+	pathPrefix = strings.TrimSuffix(pathPrefix, "/")
+	if pathPrefix == "" {
+		pathPrefix = "/"
+	}
+
+	byPrefix := backends[host]
+	if byPrefix == nil {
+		byPrefix = map[string]*backendSpec{}
+		backends[host] = byPrefix
+	}
+
+	spec := byPrefix[pathPrefix]
+	if spec == nil {
+		spec = &backendSpec{}
+		byPrefix[pathPrefix] = spec
+	}
+
+	if isOption {
+		if _, err := spec.options.Set(last, value); err != nil {
+			*errs = append(*errs, fmt.Sprintf("kv: backend %s%s: option %s: %v", host, pathPrefix, last, err))
+		}
+		return
+	}
+
+	spec.targets = splitTargets(value)
+}
+
+// frontendSpec is one "frontends/<name>" entry: a host/path-prefix pair
+// routed to a named, possibly shared, backend (see buildSnapshot).
+type frontendSpec struct {
+	host    string
+	path    string
+	backend string
+	options config.BackendOptions
+}
+
+// applyFrontendKey folds one "frontends/<name>/..." entry into frontends,
+// recognizing the "host", "path", "backend" and "options/<name>" leaves.
+func applyFrontendKey(frontends map[string]*frontendSpec, name string, rest []string, value string, errs *[]string) {
+	if len(rest) == 0 {
+		return
+	}
+
+	fe := frontends[name]
+	if fe == nil {
+		fe = &frontendSpec{}
+		frontends[name] = fe
+	}
+
+	if len(rest) == 2 && rest[0] == "options" {
+		if _, err := fe.options.Set(rest[1], value); err != nil {
+			*errs = append(*errs, fmt.Sprintf("kv: frontend %s: option %s: %v", name, rest[1], err))
+		}
+		return
+	}
+
+	if len(rest) != 1 {
+		return
+	}
+
+	switch rest[0] {
+	case "host":
+		fe.host = value
+	case "path":
+		fe.path = value
+	case "backend":
+		fe.backend = value
+	}
+}
+
+func splitTargets(value string) []string {
+	var targets []string
+	for _, t := range strings.Split(value, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			targets = append(targets, t)
+		}
+	}
+	return targets
+}
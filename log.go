@@ -3,7 +3,6 @@ package main
 import (
 	"bytes"
 	"fmt"
-	"log"
 )
 
 var (
@@ -14,15 +13,6 @@ type Loggable interface {
 	ToLog(*LogMessage)
 }
 
-func processLog() {
-	buf := &bytes.Buffer{}
-	for loggable := range logCh {
-		loggable.ToLog((*LogMessage)(buf))
-		log.Print(buf.String())
-		buf.Reset()
-	}
-}
-
 type LogMessage bytes.Buffer
 
 func (l *LogMessage) Field(name string, value interface{}) *LogMessage {
@@ -12,8 +12,10 @@ import (
 	"os"
 	"os/signal"
 	"runtime/pprof"
+	"sync"
 	"syscall"
 
+	"github.com/mcluseau/kingress/config"
 	"github.com/mcluseau/kingress/k8s"
 )
 
@@ -42,6 +44,9 @@ func main() {
 
 	log.Print("Starting...")
 
+	startACME()
+	startRateLimiting()
+
 	// seed math/rand
 	{
 		v, err := crand.Int(crand.Reader, big.NewInt(math.MaxInt64))
@@ -100,8 +105,27 @@ func main() {
 		}()
 	}
 
-	// Start watching kubernetes
-	k8s.Start(hosts)
+	// Start every configuration provider (Kubernetes, plus any KV store
+	// configured through -kv-consul-addr/-kv-etcd-addr), stopping them all
+	// together on shutdown.
+	stop := make(chan struct{})
+	providersWG := &sync.WaitGroup{}
+
+	// Register is called synchronously, in the order providers should be
+	// merged in, before any of them starts watching for changes in its own
+	// goroutine -- so that order doesn't end up decided by the scheduler.
+	runProvider := func(p config.Provider) {
+		p.Register()
+
+		providersWG.Add(1)
+		go func() {
+			defer providersWG.Done()
+			p.Run(stop)
+		}()
+	}
+
+	runProvider(k8s.Provider{Hosts: hosts})
+	startKVProviders(runProvider)
 
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
@@ -109,5 +133,6 @@ func main() {
 	sig := <-sigs
 	log.Printf("Got signal %s, exiting.", sig)
 
-	k8s.Stop()
+	close(stop)
+	providersWG.Wait()
 }
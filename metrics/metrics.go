@@ -0,0 +1,147 @@
+// Package metrics exposes kingress's Prometheus collectors: request counts
+// and latency, in-flight requests, backend connection errors, TLS
+// handshakes and certificate expiry. Handler serves them for the existing
+// API listener's /metrics endpoint.
+package metrics
+
+import (
+	"flag"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	buckets = flag.String("metrics-buckets", "", "Comma-separated request duration histogram buckets, in seconds (default: Prometheus' standard buckets)")
+
+	exemplars = flag.Bool("metrics-exemplars", false,
+		"Attach an OpenMetrics exemplar (the request ID) to each request duration observation")
+)
+
+var registry = prometheus.NewRegistry()
+
+var (
+	labels = []string{"ingress", "host", "method", "status", "proto"}
+
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kingress_requests_total",
+		Help: "Total number of requests handled.",
+	}, labels)
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kingress_request_duration_seconds",
+		Help:    "Request handling duration, from the proxy receiving the request to the response being fully written.",
+		Buckets: histogramBuckets(),
+	}, labels)
+
+	inFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kingress_requests_in_flight",
+		Help: "Number of requests currently being handled.",
+	}, []string{"ingress", "host", "proto"})
+
+	backendErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kingress_backend_errors_total",
+		Help: "Total number of errors connecting to or communicating with a backend target.",
+	}, []string{"ingress", "host", "proto"})
+
+	tlsHandshakes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kingress_tls_handshakes_total",
+		Help: "Total number of client TLS handshakes, by negotiated version and result.",
+	}, []string{"version", "result"})
+
+	certExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kingress_certificate_expiry_timestamp_seconds",
+		Help: "Configured certificate's notAfter, as a Unix timestamp, by host.",
+	}, []string{"host"})
+)
+
+func init() {
+	registry.MustRegister(
+		requestsTotal,
+		requestDuration,
+		inFlight,
+		backendErrors,
+		tlsHandshakes,
+		certExpiry,
+	)
+}
+
+// histogramBuckets parses -metrics-buckets, falling back to Prometheus'
+// standard buckets when unset or invalid.
+func histogramBuckets() []float64 {
+	if *buckets == "" {
+		return prometheus.DefBuckets
+	}
+
+	parts := strings.Split(*buckets, ",")
+	parsed := make([]float64, 0, len(parts))
+
+	for _, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return prometheus.DefBuckets
+		}
+		parsed = append(parsed, v)
+	}
+
+	return parsed
+}
+
+// ObserveRequest records a completed request's outcome and duration. When
+// -metrics-exemplars is set and requestID is non-empty, it's attached to
+// the duration observation as an OpenMetrics exemplar.
+func ObserveRequest(ingress, host, method string, status int, proto string, duration time.Duration, requestID string) {
+	lvs := []string{ingress, host, method, strconv.Itoa(status), proto}
+
+	requestsTotal.WithLabelValues(lvs...).Inc()
+
+	obs := requestDuration.WithLabelValues(lvs...)
+
+	if *exemplars && requestID != "" {
+		if eo, ok := obs.(prometheus.ExemplarObserver); ok {
+			eo.ObserveWithExemplar(duration.Seconds(), prometheus.Labels{"req": requestID})
+			return
+		}
+	}
+
+	obs.Observe(duration.Seconds())
+}
+
+// IncInFlight and DecInFlight track the number of requests currently being
+// handled for a given ingress/host/proto.
+func IncInFlight(ingress, host, proto string) { inFlight.WithLabelValues(ingress, host, proto).Inc() }
+func DecInFlight(ingress, host, proto string) { inFlight.WithLabelValues(ingress, host, proto).Dec() }
+
+// ObserveBackendError records a failure connecting to or communicating
+// with a backend target.
+func ObserveBackendError(ingress, host, proto string) {
+	backendErrors.WithLabelValues(ingress, host, proto).Inc()
+}
+
+// ObserveTLSHandshake records one client TLS handshake, by its negotiated
+// version name (see tlsVersionName) and whether it succeeded.
+func ObserveTLSHandshake(version string, ok bool) {
+	result := "success"
+	if !ok {
+		result = "failure"
+	}
+	tlsHandshakes.WithLabelValues(version, result).Inc()
+}
+
+// SetCertExpiry records host's configured certificate's notAfter.
+func SetCertExpiry(host string, notAfter time.Time) {
+	certExpiry.WithLabelValues(host).Set(float64(notAfter.Unix()))
+}
+
+// Handler serves the registered collectors, as OpenMetrics (with
+// exemplars) when -metrics-exemplars is set, or plain Prometheus text
+// format otherwise.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{
+		EnableOpenMetrics: *exemplars,
+	})
+}
@@ -1,16 +1,25 @@
 package main
 
 import (
+	"bufio"
+	"context"
 	"crypto/tls"
 	"flag"
+	"fmt"
+	"io"
 	"log"
 	"net"
 	"net/http"
-	"net/url"
-	"strings"
+	"net/http/httptrace"
+	"sync"
 	"time"
 
+	"github.com/mcluseau/kingress/config"
+	"github.com/mcluseau/kingress/metrics"
 	"github.com/vulcand/oxy/forward"
+	"github.com/vulcand/oxy/utils"
+	"golang.org/x/net/http2"
+	"google.golang.org/grpc"
 )
 
 var (
@@ -22,12 +31,38 @@ var (
 If zero, no periodic flushing is done.
 A negative value (ie: -1ns) means to flush immediately.
 Ignored when a response is recognized as a streaming response; for such reponses, writes are flushed immediately.`)
+
+	http2PingInterval = flag.Duration("http2-ping-interval", 0,
+		"Backend HTTP/2 connections: interval between health-check pings when no other frame is received (0 disables the health check).")
+	http2StrictMaxConcurrentStreams = flag.Bool("http2-strict-max-concurrent-streams", false,
+		"Backend HTTP/2 connections: treat the backend's SETTINGS_MAX_CONCURRENT_STREAMS as a global per-backend limit instead of opening new connections past it.")
 )
 
 type oxyHandler struct {
 	Proto string
 	Port  string
 	fwd   *forward.Forwarder
+
+	// h2cFwd forwards to backends over h2c (cleartext HTTP/2), for backends
+	// with the http2-cleartext option set (see BackendOptions.HTTP2Cleartext).
+	h2cFwd *forward.Forwarder
+
+	grpcL   sync.Mutex
+	grpcSrv *grpc.Server
+}
+
+// backendCtxKey keys the request context value holding the backend chosen
+// for this request, so the forwarder's ErrorHandler can attribute a
+// backend connection error to it (see errorHandler).
+type backendCtxKey struct{}
+
+func errorHandler(proto string) utils.ErrorHandler {
+	return utils.ErrorHandlerFunc(func(w http.ResponseWriter, r *http.Request, err error) {
+		if backend, ok := r.Context().Value(backendCtxKey{}).(*config.Backend); ok {
+			metrics.ObserveBackendError(backend.IngressRef, r.Host, proto)
+		}
+		utils.DefaultHandler.ServeHTTP(w, r, err)
+	})
 }
 
 func newOxyHandler(proto, port string) http.Handler {
@@ -37,6 +72,19 @@ func newOxyHandler(proto, port string) http.Handler {
 		forward.WebsocketTLSClientConfig(tlsConfig),
 		forward.Stream(true),
 		forward.StreamingFlushInterval(*flushInterval),
+		forward.ErrorHandler(errorHandler(proto)),
+	)
+	if err != nil {
+		panic(err) // what can it be?
+	}
+
+	h2cFwd, err := forward.New(
+		forward.PassHostHeader(true),
+		forward.RoundTripper(h2cRoundTripper()),
+		forward.WebsocketTLSClientConfig(tlsConfig),
+		forward.Stream(true),
+		forward.StreamingFlushInterval(*flushInterval),
+		forward.ErrorHandler(errorHandler(proto)),
 	)
 	if err != nil {
 		panic(err) // what can it be?
@@ -46,13 +94,21 @@ func newOxyHandler(proto, port string) http.Handler {
 		Proto: proto,
 		Port:  port,
 
-		fwd: fwd,
+		fwd:    fwd,
+		h2cFwd: h2cFwd,
 	}
 }
 
 func roundTripper() http.RoundTripper {
-	return &http.Transport{
-		TLSClientConfig: tlsConfig,
+	t := newBackendTransport(tlsConfig)
+	return &sslOverrideRoundTripper{base: t}
+}
+
+// newBackendTransport builds an *http.Transport using tlsCfg for secure
+// backends, with HTTP/2-over-ALPN negotiation enabled.
+func newBackendTransport(tlsCfg *tls.Config) *http.Transport {
+	t := &http.Transport{
+		TLSClientConfig: tlsCfg,
 		// below are the defaults
 		Proxy: http.ProxyFromEnvironment,
 		DialContext: (&net.Dialer{
@@ -65,6 +121,136 @@ func roundTripper() http.RoundTripper {
 		TLSHandshakeTimeout:   10 * time.Second,
 		ExpectContinueTimeout: 1 * time.Second,
 	}
+
+	// let TLS backends negotiate HTTP/2 over ALPN instead of being stuck on
+	// HTTP/1.1; backends speaking plain HTTP/1.1 are unaffected.
+	if err := http2.ConfigureTransport(t); err != nil {
+		log.Print("warning: failed to configure backend transport for HTTP/2: ", err)
+	}
+
+	return t
+}
+
+// sslOverrideRoundTripper uses base for every request, except when the
+// resolved backend (stashed in context by ServeHTTP, see backendCtxKey) has
+// ssl-min-protocol-version/ssl-ciphers overrides, in which case it builds a
+// one-off transport for that request with them applied. This trades away
+// connection reuse for such requests, which is fine given how rarely a
+// single ingress needs its own TLS floor.
+type sslOverrideRoundTripper struct {
+	base *http.Transport
+}
+
+func (rt *sslOverrideRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	backend, ok := r.Context().Value(backendCtxKey{}).(*config.Backend)
+	if !ok || !backend.Options.SecureBackends ||
+		(backend.Options.SSLMinProtocolVersion == 0 && len(backend.Options.SSLCipherSuites) == 0) {
+		return rt.base.RoundTrip(r)
+	}
+
+	cfg := rt.base.TLSClientConfig.Clone()
+	applySSLOverrides(cfg, backend.Options, "oxy-handler")
+
+	return newBackendTransport(cfg).RoundTrip(r)
+}
+
+// h2cRoundTripper builds the RoundTripper used for backends with the
+// http2-cleartext option set: an http2.Transport forced to speak HTTP/2
+// over a plain (non-TLS) connection, since net/http's own Transport can't.
+func h2cRoundTripper() http.RoundTripper {
+	return &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+		ReadIdleTimeout:            *http2PingInterval,
+		StrictMaxConcurrentStreams: *http2StrictMaxConcurrentStreams,
+	}
+}
+
+// statusCapturingResponseWriter remembers the response status and the bytes
+// written to the client, so the chosen target's BackendPolicy can be told
+// whether the request succeeded and the access log can record its size.
+type statusCapturingResponseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *statusCapturingResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusCapturingResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush and Hijack pass through to the underlying ResponseWriter so wrapping
+// it here doesn't break forward.Stream's explicit flushing (streaming/SSE
+// responses, h2c/gRPC) or gorilla/websocket's Upgrader, which type-asserts
+// http.Hijacker on every WebSocket request.
+func (w *statusCapturingResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *statusCapturingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("underlying ResponseWriter does not support Hijack")
+	}
+	return h.Hijack()
+}
+
+// countingReadCloser counts the bytes read from the request body, for the
+// access log's bytes-in field.
+type countingReadCloser struct {
+	io.ReadCloser
+	n int64
+}
+
+func (c *countingReadCloser) Read(b []byte) (int, error) {
+	n, err := c.ReadCloser.Read(b)
+	c.n += int64(n)
+	return n, err
+}
+
+// connectTiming captures how long dialing the chosen upstream took, via an
+// httptrace.ClientTrace installed on the outgoing request's context.
+type connectTiming struct {
+	mu              sync.Mutex
+	connectStart    time.Time
+	connectDuration time.Duration
+}
+
+func (t *connectTiming) trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		ConnectStart: func(network, addr string) {
+			t.mu.Lock()
+			t.connectStart = time.Now()
+			t.mu.Unlock()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			t.mu.Lock()
+			if !t.connectStart.IsZero() {
+				t.connectDuration = time.Since(t.connectStart)
+			}
+			t.mu.Unlock()
+		},
+	}
+}
+
+func (t *connectTiming) duration() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.connectDuration
 }
 
 func (h *oxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -76,90 +262,98 @@ func (h *oxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	req := newRequest(h.Proto)
 
-	backend, target, status := getBackend(r)
-	if status != 0 {
-		// no backend matching
-		http.Error(w, http.StatusText(status), status)
-		return
+	entry := &AccessLogEntry{
+		Request:     req,
+		Remote:      r.RemoteAddr,
+		Proto:       r.Proto,
+		Host:        r.Host,
+		Method:      r.Method,
+		URI:         r.RequestURI,
+		HTTPVersion: r.Proto,
+		Referer:     r.Referer(),
+		UserAgent:   r.UserAgent(),
 	}
 
-	startLog := &RequestStartLog{
-		Request: req,
-		Remote:  r.RemoteAddr,
-		Proto:   r.Proto,
-		Host:    r.Host,
-		Method:  r.Method,
-		URI:     r.RequestURI,
-		Ingress: backend.IngressRef,
-		Target:  target,
-		Reject:  allowRequest(backend, h.Proto, w, r),
+	if r.TLS != nil {
+		entry.TLSServerName = r.TLS.ServerName
+		entry.TLSVersion = tlsVersionName(r.TLS.Version)
 	}
 
-	logCh <- startLog
+	var sampleRate uint32
+
+	defer func() {
+		entry.Duration = req.Clock()
+		sendAccessLog(entry, sampleRate)
+		metrics.ObserveRequest(entry.Ingress, entry.Host, entry.Method, entry.Status, h.Proto, entry.Duration, req.ID)
+	}()
 
-	if len(startLog.Reject) != 0 {
+	backend, target, status := getBackend(r)
+	if status != 0 {
+		// no backend matching
+		entry.Status = status
+		http.Error(w, http.StatusText(status), status)
 		return
 	}
 
-	if len(backend.Options.CORSAllowedOrigins) != 0 &&
-		r.Method == http.MethodOptions &&
-		r.Header.Get("Access-Control-Request-Method") != "" {
-		// handle CORS response here
-		hdr := w.Header()
-		origin := r.Header.Get("Origin")
-
-		allowed := false
-
-		if originURL, err := url.Parse(origin); err == nil {
-			for _, allowedOrigin := range backend.Options.CORSAllowedOrigins {
-				if len(allowedOrigin) == 0 {
-					continue
-				}
-
-				suffix, hasWildcardPrefix := strings.CutPrefix(allowedOrigin, "*")
-				if hasWildcardPrefix {
-					if strings.HasSuffix(originURL.Hostname(), suffix) {
-						allowed = true
-						break
-					}
-				} else {
-					if origin == allowedOrigin {
-						allowed = true
-						break
-					}
-				}
-			}
-		}
+	sampleRate = backend.Options.AccessLogSample
+	entry.Ingress = backend.IngressRef
+	entry.Target = target
 
-		if !allowed {
-			http.Error(w, "origin not allowed\n", http.StatusForbidden)
-			return
-		}
+	r = r.WithContext(context.WithValue(r.Context(), backendCtxKey{}, backend))
 
-		hdr.Set("Access-Control-Allow-Origin", origin)
+	metrics.IncInFlight(backend.IngressRef, r.Host, h.Proto)
+	defer metrics.DecInFlight(backend.IngressRef, r.Host, h.Proto)
 
-		hdr.Add("Vary", "Access-Control-Request-Method")
-		hdr.Add("Vary", "Access-Control-Request-Headers")
+	entry.Reject, entry.Status = allowRequest(backend, h.Proto, w, r)
 
-		hdr.Set("Access-Control-Allow-Credentials", "true")
-		hdr.Set("Access-Control-Allow-Headers", "*")
+	if len(entry.Reject) != 0 {
+		return
+	}
 
-		w.WriteHeader(http.StatusNoContent)
+	if backend.Options.LimitConnections > 0 {
+		defer limiterFor(backend).release(backend.Options)
+	}
 
+	if applyCORS(backend, w, r) {
+		entry.Status = http.StatusNoContent
 		return
 	}
 
-	r.URL.Host = backend.Target()
+	if backend.Match != nil {
+		r.URL.Path = backend.Match.RewritePath(r.URL.Path)
+		r.RequestURI = r.URL.RequestURI()
+	}
+
+	r.URL.Host = target
 	r.URL.Scheme = "http"
 
 	if backend.Options.SecureBackends {
 		r.URL.Scheme = "https"
 	}
 
-	h.fwd.ServeHTTP(w, r)
+	body := &countingReadCloser{ReadCloser: r.Body}
+	r.Body = body
+
+	timing := &connectTiming{}
+	r = r.WithContext(httptrace.WithClientTrace(r.Context(), timing.trace()))
+
+	sw := &statusCapturingResponseWriter{ResponseWriter: w}
+
+	fwd := h.fwd
+	if backend.Options.HTTP2Cleartext || (backend.Options.GRPCBackend && !backend.Options.SecureBackends) {
+		fwd = h.h2cFwd
+	}
+
+	fwd.ServeHTTP(sw, r)
+
+	entry.Status = sw.status
+	entry.BytesIn = body.n
+	entry.BytesOut = sw.bytes
+	entry.ConnectTime = timing.duration()
 
-	logCh <- &RequestEndLog{
-		Request: req,
-		Time:    req.Clock(),
+	if sw.status >= 500 {
+		backend.Policy().MarkFailure(target)
+	} else {
+		backend.Policy().MarkSuccess(target)
 	}
 }
@@ -11,27 +11,37 @@ import (
 type ForwardHandler struct {
 	Network string
 	Target  string
+
+	// SendProxyProtocol, when true, emits a PROXY protocol v2 header to the
+	// backend ahead of alreadyRead, carrying req.ClientAddr (falling back to
+	// src's remote address if the incoming connection had none).
+	SendProxyProtocol bool
 }
 
 func (h ForwardHandler) Handle(req Request, alreadyRead []byte, src net.Conn) (handled bool) {
 	dst, err := net.Dial(h.Network, h.Target)
 
-	return Forward(req, alreadyRead, src, dst, err, h.Network+"://"+h.Target+": ")
+	return Forward(req, alreadyRead, src, dst, err, h.Network+"://"+h.Target+": ", h.SendProxyProtocol)
 }
 
 type TLSForwardHandler struct {
 	Network string
 	Target  string
 	Config  *tls.Config
+
+	// SendProxyProtocol, when true, emits a PROXY protocol v2 header to the
+	// backend ahead of alreadyRead, carrying req.ClientAddr (falling back to
+	// src's remote address if the incoming connection had none).
+	SendProxyProtocol bool
 }
 
 func (h TLSForwardHandler) Handle(req Request, alreadyRead []byte, src net.Conn) bool {
 	dst, err := tls.Dial(h.Network, h.Target, h.Config)
 
-	return Forward(req, alreadyRead, src, dst, err, h.Network+"://"+h.Target+": ")
+	return Forward(req, alreadyRead, src, dst, err, h.Network+"://"+h.Target+": ", h.SendProxyProtocol)
 }
 
-func Forward(req Request, alreadyRead []byte, src, dst net.Conn, err error, logPrefix string) (handled bool) {
+func Forward(req Request, alreadyRead []byte, src, dst net.Conn, err error, logPrefix string, sendProxyProtocol bool) (handled bool) {
 	handled = true
 
 	logf := func(pattern string, values ...any) {
@@ -48,6 +58,18 @@ func Forward(req Request, alreadyRead []byte, src, dst net.Conn, err error, logP
 
 	defer dst.Close()
 
+	if sendProxyProtocol {
+		clientAddr := req.ClientAddr
+		if clientAddr == nil {
+			clientAddr = src.RemoteAddr()
+		}
+
+		if err = writeProxyProtocolV2(dst, clientAddr); err != nil {
+			logf("proxy protocol write error: %v", err)
+			return
+		}
+	}
+
 	_, err = dst.Write(alreadyRead)
 	if err != nil {
 		logf("write error: %v", err)
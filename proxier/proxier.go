@@ -57,6 +57,11 @@ type Request struct {
 	Path        string
 	Host        string
 	HTTPVersion string
+
+	// ClientAddr is the real client address carried by a PROXY protocol
+	// preamble, when Proxier.ProxyProtocol is enabled; nil otherwise (or for
+	// a LOCAL/UNKNOWN preamble, which carries no usable address).
+	ClientAddr net.Addr
 }
 
 type Handler interface {
@@ -83,6 +88,18 @@ type Proxier struct {
 	// use `http.ReadRequest(alreadyRead)` to build get the standard request object.
 	ReadMinimum bool
 
+	// ProxyProtocol makes Handle expect a PROXY protocol v1 or v2 preamble
+	// ahead of every connection's HTTP request line, populating Request.ClientAddr
+	// from it. It assumes every accepted connection carries the preamble (as a
+	// downstream L4 load balancer configured for it would send); enable it only
+	// behind such a load balancer, gated by TrustedProxyCIDRs.
+	ProxyProtocol bool
+
+	// TrustedProxyCIDRs restricts which peers Handle accepts a PROXY protocol
+	// preamble from. A connection from outside these networks is handled as
+	// plain HTTP even when ProxyProtocol is set. Empty means trust any peer.
+	TrustedProxyCIDRs []*net.IPNet
+
 	l        sync.Mutex
 	handlers []Handler
 }
@@ -97,13 +114,29 @@ func (p *Proxier) Handle(conn net.Conn, requireHost string) {
 		conn.SetReadDeadline(time.Now().Add(timeout))
 	}
 
-	reader := io.LimitReader(conn, p.ReadLimit)
+	req := Request{}
+
+	var source io.Reader = conn
+
+	if p.ProxyProtocol && p.trustsPeer(conn.RemoteAddr()) {
+		clientAddr, extra, err := readProxyProtocolHeader(conn, maxProxyProtocolHeader)
+		if err != nil {
+			if Verbose {
+				log.Print("invalid PROXY protocol header from ", conn.RemoteAddr(), ": ", err)
+			}
+			conn.Write(InvalidProtocolResponse)
+			return
+		}
+
+		req.ClientAddr = clientAddr
+		source = io.MultiReader(bytes.NewReader(extra), conn)
+	}
+
+	reader := io.LimitReader(source, p.ReadLimit)
 
 	allRead := make([]byte, 0, 4096)
 	buf := make([]byte, 4096)
 
-	req := Request{}
-
 	lineStart := 0
 	onRequestLine := true
 
@@ -229,6 +262,10 @@ readLoop:
 	conn.Write(NoHandlerResponse)
 }
 
+func (p *Proxier) trustsPeer(addr net.Addr) bool {
+	return trustsPeer(p.TrustedProxyCIDRs, addr)
+}
+
 func (p *Proxier) AddHandlers(handlers ...Handler) {
 	p.l.Lock()
 	p.handlers = append(p.handlers, handlers...)
@@ -0,0 +1,280 @@
+package proxier
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// maxProxyProtocolHeader caps how many bytes readProxyProtocolHeader will
+// read while looking for a PROXY protocol preamble, independent of
+// Proxier.ReadLimit: a malformed or hostile preamble must not be able to
+// consume the request's own read budget before being rejected.
+const maxProxyProtocolHeader = 256
+
+var proxyProtocolV2Sig = []byte{0x0D, 0x0A, 0x0D, 0x0A, 0x00, 0x0D, 0x0A, 0x51, 0x55, 0x49, 0x54, 0x0A}
+
+// readProxyProtocolHeader reads a PROXY protocol v1 or v2 preamble from conn.
+// It returns the parsed client address (nil for UNKNOWN/LOCAL) along with
+// any bytes read past the header that belong to the connection's actual data
+// stream. A connection that doesn't start with a recognizable preamble
+// yields an error.
+func readProxyProtocolHeader(conn net.Conn, maxLen int) (clientAddr net.Addr, extra []byte, err error) {
+	buf := make([]byte, 0, maxLen)
+	tmp := make([]byte, maxLen)
+
+	for {
+		n, rErr := conn.Read(tmp)
+		if n > 0 {
+			buf = append(buf, tmp[:n]...)
+		}
+		if rErr != nil {
+			return nil, nil, rErr
+		}
+
+		if bytes.HasPrefix(proxyProtocolV2Sig, buf) && len(buf) < len(proxyProtocolV2Sig) {
+			// not enough bytes yet to tell v1 from v2
+			if len(buf) >= maxLen {
+				return nil, nil, errors.New("proxy protocol: header too large")
+			}
+			continue
+		}
+
+		if bytes.HasPrefix(buf, proxyProtocolV2Sig) {
+			return readProxyProtocolV2(conn, buf, maxLen)
+		}
+
+		if idx := bytes.Index(buf, []byte("\r\n")); idx != -1 {
+			return parseProxyProtocolV1(buf[:idx], buf[idx+2:])
+		}
+
+		if len(buf) >= maxLen {
+			return nil, nil, errors.New("proxy protocol: no header found within size cap")
+		}
+	}
+}
+
+// parseProxyProtocolV1 parses the text header line (without its trailing
+// "\r\n"), e.g. "PROXY TCP4 192.0.2.1 192.0.2.2 56324 443".
+func parseProxyProtocolV1(line, extra []byte) (net.Addr, []byte, error) {
+	fields := strings.Fields(string(line))
+	if len(fields) < 2 || fields[0] != "PROXY" {
+		return nil, nil, errors.New("proxy protocol: malformed v1 header")
+	}
+
+	if fields[1] == "UNKNOWN" {
+		return nil, extra, nil
+	}
+
+	if len(fields) != 6 {
+		return nil, nil, errors.New("proxy protocol: malformed v1 header")
+	}
+
+	srcIP := net.ParseIP(fields[2])
+	srcPort, err := strconv.Atoi(fields[4])
+	if srcIP == nil || err != nil {
+		return nil, nil, errors.New("proxy protocol: bad v1 source address")
+	}
+
+	return &net.TCPAddr{IP: srcIP, Port: srcPort}, extra, nil
+}
+
+// readProxyProtocolV2 reads (and, if needed, keeps reading) the fixed v2
+// header plus its address block, once buf already starts with the 12-byte
+// signature.
+func readProxyProtocolV2(conn net.Conn, buf []byte, maxLen int) (net.Addr, []byte, error) {
+	tmp := make([]byte, maxLen)
+
+	for len(buf) < 16 {
+		n, err := conn.Read(tmp)
+		if n > 0 {
+			buf = append(buf, tmp[:n]...)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	verCmd := buf[12]
+	fam := buf[13]
+	addrLen := int(binary.BigEndian.Uint16(buf[14:16]))
+	total := 16 + addrLen
+
+	if total > maxLen {
+		return nil, nil, errors.New("proxy protocol: v2 header exceeds size cap")
+	}
+
+	for len(buf) < total {
+		n, err := conn.Read(tmp)
+		if n > 0 {
+			buf = append(buf, tmp[:n]...)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	extra := append([]byte(nil), buf[total:]...)
+
+	if verCmd>>4 != 2 {
+		return nil, nil, errors.New("proxy protocol: unsupported v2 version")
+	}
+
+	if verCmd&0x0F == 0x00 {
+		// LOCAL command: health check or similar, no real client address
+		return nil, extra, nil
+	}
+
+	addr := buf[16:total]
+
+	switch fam >> 4 {
+	case 1: // AF_INET
+		if len(addr) < 12 {
+			return nil, nil, errors.New("proxy protocol: short v2 ipv4 address block")
+		}
+		return &net.TCPAddr{
+			IP:   net.IP(append([]byte(nil), addr[0:4]...)),
+			Port: int(binary.BigEndian.Uint16(addr[8:10])),
+		}, extra, nil
+
+	case 2: // AF_INET6
+		if len(addr) < 36 {
+			return nil, nil, errors.New("proxy protocol: short v2 ipv6 address block")
+		}
+		return &net.TCPAddr{
+			IP:   net.IP(append([]byte(nil), addr[0:16]...)),
+			Port: int(binary.BigEndian.Uint16(addr[32:34])),
+		}, extra, nil
+
+	default: // AF_UNSPEC, AF_UNIX: no usable network address
+		return nil, extra, nil
+	}
+}
+
+// Listener wraps a net.Listener, peeling an optional PROXY protocol v1/v2
+// preamble off each accepted connection and exposing the real client address
+// through the returned net.Conn's RemoteAddr. It's meant for servers (such as
+// net/http's) that read the connection themselves, unlike Proxier.Handle
+// which can parse the preamble inline.
+type Listener struct {
+	net.Listener
+
+	// TrustedProxyCIDRs restricts preamble acceptance to these source
+	// networks, like Proxier's field of the same name. Empty trusts any peer.
+	TrustedProxyCIDRs []*net.IPNet
+}
+
+func (l *Listener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	if !trustsPeer(l.TrustedProxyCIDRs, conn.RemoteAddr()) {
+		return conn, nil
+	}
+
+	clientAddr, extra, err := readProxyProtocolHeader(conn, maxProxyProtocolHeader)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &proxyProtocolConn{
+		Conn:       conn,
+		extra:      extra,
+		clientAddr: clientAddr,
+	}, nil
+}
+
+func trustsPeer(trusted []*net.IPNet, addr net.Addr) bool {
+	if len(trusted) == 0 {
+		return true
+	}
+
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return false
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, cidr := range trusted {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// proxyProtocolConn serves up the bytes read past a PROXY protocol preamble
+// before falling through to the underlying conn, and reports the preamble's
+// client address (if any) as its RemoteAddr.
+type proxyProtocolConn struct {
+	net.Conn
+	extra      []byte
+	clientAddr net.Addr
+}
+
+func (c *proxyProtocolConn) Read(b []byte) (int, error) {
+	if len(c.extra) > 0 {
+		n := copy(b, c.extra)
+		c.extra = c.extra[n:]
+		return n, nil
+	}
+
+	return c.Conn.Read(b)
+}
+
+func (c *proxyProtocolConn) RemoteAddr() net.Addr {
+	if c.clientAddr != nil {
+		return c.clientAddr
+	}
+
+	return c.Conn.RemoteAddr()
+}
+
+// writeProxyProtocolV2 emits a PROXY protocol v2 header describing src ahead
+// of the proxied connection's own bytes, so the backend sees the original
+// client address. A nil or non-TCP src yields a LOCAL header (no address).
+func writeProxyProtocolV2(w interface{ Write([]byte) (int, error) }, src net.Addr) error {
+	hdr := make([]byte, 0, 28)
+	hdr = append(hdr, proxyProtocolV2Sig...)
+
+	tcpAddr, _ := src.(*net.TCPAddr)
+
+	if tcpAddr == nil {
+		hdr = append(hdr, 0x20, 0x00, 0x00, 0x00) // v2, LOCAL, AF_UNSPEC/UNSPEC, len 0
+		_, err := w.Write(hdr)
+		return err
+	}
+
+	ip4 := tcpAddr.IP.To4()
+
+	if ip4 != nil {
+		hdr = append(hdr, 0x21, 0x11) // v2, PROXY, AF_INET/STREAM
+		hdr = binary.BigEndian.AppendUint16(hdr, 12)
+		hdr = append(hdr, ip4...)
+		hdr = append(hdr, ip4...) // we don't track our own dst address; repeat src
+		hdr = binary.BigEndian.AppendUint16(hdr, uint16(tcpAddr.Port))
+		hdr = binary.BigEndian.AppendUint16(hdr, uint16(tcpAddr.Port))
+	} else {
+		ip6 := tcpAddr.IP.To16()
+		hdr = append(hdr, 0x21, 0x21) // v2, PROXY, AF_INET6/STREAM
+		hdr = binary.BigEndian.AppendUint16(hdr, 36)
+		hdr = append(hdr, ip6...)
+		hdr = append(hdr, ip6...)
+		hdr = binary.BigEndian.AppendUint16(hdr, uint16(tcpAddr.Port))
+		hdr = binary.BigEndian.AppendUint16(hdr, uint16(tcpAddr.Port))
+	}
+
+	_, err := w.Write(hdr)
+	return err
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"strings"
+
+	"github.com/mcluseau/kingress/proxier"
+)
+
+var (
+	proxyProtocol        = flag.Bool("proxy-protocol", false, "expect a PROXY protocol v1/v2 preamble ahead of every connection on the HTTP/HTTPS listeners")
+	proxyProtocolTrusted = flag.String("proxy-protocol-trusted-cidrs", "", "comma-separated list of CIDRs allowed to send a PROXY protocol preamble (empty: trust any peer)")
+)
+
+// maybeProxyProtocol wraps listener with a proxier.Listener when -proxy-protocol
+// is set, so the HTTP/HTTPS servers see the original client address in
+// net.Conn.RemoteAddr(); it returns listener unchanged otherwise.
+func maybeProxyProtocol(listener net.Listener) net.Listener {
+	if !*proxyProtocol {
+		return listener
+	}
+
+	cidrs, err := parseTrustedCIDRs(*proxyProtocolTrusted)
+	if err != nil {
+		log.Fatal("bad -proxy-protocol-trusted-cidrs: ", err)
+	}
+
+	return &proxier.Listener{
+		Listener:          listener,
+		TrustedProxyCIDRs: cidrs,
+	}
+}
+
+func parseTrustedCIDRs(value string) ([]*net.IPNet, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	values := strings.Split(value, ",")
+	nets := make([]*net.IPNet, len(values))
+
+	for i, v := range values {
+		_, ipnet, err := net.ParseCIDR(strings.TrimSpace(v))
+		if err != nil {
+			return nil, err
+		}
+		nets[i] = ipnet
+	}
+
+	return nets, nil
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mcluseau/kingress/config"
+)
+
+var rateLimitTrustedProxies = flag.String("rate-limit-trusted-proxies", "",
+	"comma-separated list of CIDRs trusted to set X-Forwarded-For for rate limiting's client IP (empty: always use the connection's source IP)")
+
+// backendLimiter holds the rate-limiting state for a single backend: an
+// independent token bucket per client IP for limit-rps and limit-rpm, and a
+// shared counter for limit-connections. It's kept alive across config
+// reloads (see reconcileRateLimiters), keyed by IngressRef and path prefix
+// (see backendKey), so in-flight
+// buckets and connection counts aren't reset just because the ingress was
+// re-synced.
+type backendLimiter struct {
+	mu  sync.Mutex
+	rps map[string]*tokenBucket
+	rpm map[string]*tokenBucket
+
+	connMu sync.Mutex
+	conns  int
+}
+
+func newBackendLimiter() *backendLimiter {
+	return &backendLimiter{
+		rps: map[string]*tokenBucket{},
+		rpm: map[string]*tokenBucket{},
+	}
+}
+
+// allow reports whether a request from clientIP is allowed under opts'
+// limit-rps/limit-rpm/limit-connections, and if not, after how long the
+// client should retry.
+func (l *backendLimiter) allow(opts config.BackendOptions, clientIP string) (ok bool, retryAfter time.Duration) {
+	l.mu.Lock()
+	if opts.LimitRPS > 0 {
+		b := l.rps[clientIP]
+		if b == nil {
+			b = newTokenBucket(opts.LimitRPS)
+			l.rps[clientIP] = b
+		}
+		if allowed, wait := b.take(opts.LimitRPS); !allowed {
+			l.mu.Unlock()
+			return false, wait
+		}
+	}
+	if opts.LimitRPM > 0 {
+		ratePerSec := opts.LimitRPM / 60
+		b := l.rpm[clientIP]
+		if b == nil {
+			b = newTokenBucket(ratePerSec)
+			l.rpm[clientIP] = b
+		}
+		if allowed, wait := b.take(ratePerSec); !allowed {
+			l.mu.Unlock()
+			return false, wait
+		}
+	}
+	l.mu.Unlock()
+
+	if opts.LimitConnections > 0 {
+		l.connMu.Lock()
+		if l.conns >= opts.LimitConnections {
+			l.connMu.Unlock()
+			return false, time.Second
+		}
+		l.conns++
+		l.connMu.Unlock()
+	}
+
+	return true, 0
+}
+
+// release gives back the in-flight connection slot taken by a request that
+// allow() admitted; it's a no-op when limit-connections isn't set.
+func (l *backendLimiter) release(opts config.BackendOptions) {
+	if opts.LimitConnections <= 0 {
+		return
+	}
+
+	l.connMu.Lock()
+	l.conns--
+	l.connMu.Unlock()
+}
+
+// tokenBucket is a standard token-bucket limiter: tokens accrue at rate
+// tokens/second up to a burst of one second's worth (at least one token, so
+// rates below 1/s still admit a request now and then), and each request
+// consumes one.
+type tokenBucket struct {
+	mu         sync.Mutex
+	tokens     float64
+	lastRefill time.Time
+}
+
+func newTokenBucket(rate float64) *tokenBucket {
+	return &tokenBucket{tokens: rate, lastRefill: time.Now()}
+}
+
+func (b *tokenBucket) take(rate float64) (ok bool, retryAfter time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	elapsed := now.Sub(b.lastRefill).Seconds()
+	b.lastRefill = now
+
+	b.tokens += elapsed * rate
+	burst := rate
+	if burst < 1 {
+		burst = 1
+	}
+	if b.tokens > burst {
+		b.tokens = burst
+	}
+
+	if b.tokens < 1 {
+		missing := 1 - b.tokens
+		return false, time.Duration(missing/rate*float64(time.Second)) + time.Millisecond
+	}
+
+	b.tokens--
+	return true, 0
+}
+
+// backendKey identifies one config.Backend's limiter state. IngressRef
+// alone isn't enough: a single Ingress can define several backends (one per
+// path rule), and each must get its own buckets/connection count.
+type backendKey struct {
+	ingressRef string
+	prefix     string
+}
+
+var (
+	rateLimitersMu sync.Mutex
+	rateLimiters   = map[backendKey]*backendLimiter{}
+)
+
+func limiterFor(backend *config.Backend) *backendLimiter {
+	key := backendKey{backend.IngressRef, backend.Prefix}
+
+	rateLimitersMu.Lock()
+	defer rateLimitersMu.Unlock()
+
+	l := rateLimiters[key]
+	if l == nil {
+		l = newBackendLimiter()
+		rateLimiters[key] = l
+	}
+
+	return l
+}
+
+func init() {
+	config.OnApplied(reconcileRateLimiters)
+}
+
+// reconcileRateLimiters drops limiter state for ingresses that no longer
+// exist, keeping it (with its in-flight buckets and connection counts)
+// for ones that do.
+func reconcileRateLimiters() {
+	live := map[backendKey]bool{}
+
+	cfg := config.Current
+	for _, backends := range cfg.HostBackends {
+		for _, b := range backends {
+			live[backendKey{b.IngressRef, b.Prefix}] = true
+		}
+	}
+	for _, b := range cfg.RouteBackends {
+		live[backendKey{b.IngressRef, b.Prefix}] = true
+	}
+
+	rateLimitersMu.Lock()
+	defer rateLimitersMu.Unlock()
+
+	for key := range rateLimiters {
+		if !live[key] {
+			delete(rateLimiters, key)
+		}
+	}
+}
+
+// rateLimitClientIP returns the IP rate limiting should key on: the
+// connection's source IP, or the left-most address in X-Forwarded-For when
+// the source is a trusted proxy (see -rate-limit-trusted-proxies).
+func rateLimitClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" && isTrustedProxy(host) {
+		if first, _, found := strings.Cut(xff, ","); found || first != "" {
+			return strings.TrimSpace(first)
+		}
+	}
+
+	return host
+}
+
+var trustedProxies []*net.IPNet
+
+// startRateLimiting validates -rate-limit-trusted-proxies up front, so a bad
+// flag value fails fast at startup instead of on the first request.
+func startRateLimiting() {
+	nets, err := parseTrustedCIDRs(*rateLimitTrustedProxies)
+	if err != nil {
+		log.Fatal("bad -rate-limit-trusted-proxies: ", err)
+	}
+	trustedProxies = nets
+}
+
+func isTrustedProxy(host string) bool {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, ipnet := range trustedProxies {
+		if ipnet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
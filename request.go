@@ -35,21 +35,40 @@ func (r *request) ToLog(message *LogMessage) {
 		Field("req", r.ID)
 }
 
-type RequestStartLog struct {
+// AccessLogEntry is the single record emitted for a handled HTTP(S) request,
+// once it's been fully answered. The raw TCP/TLS passthrough path
+// (proxier.Proxier.Handle/Forward) emits no such record.
+type AccessLogEntry struct {
 	Request *request
-	Remote  string
-	Proto   string
-	Host    string
-	Method  string
-	URI     string
+
+	Remote      string
+	Proto       string
+	Host        string
+	Method      string
+	URI         string
+	HTTPVersion string
+
+	TLSServerName string
+	TLSVersion    string
+
 	Ingress string
 	Target  string
 	Reject  string
+
+	Referer   string
+	UserAgent string
+
+	Status   int
+	BytesIn  int64
+	BytesOut int64
+
+	ConnectTime time.Duration
+	Duration    time.Duration
 }
 
-var _ Loggable = &RequestStartLog{}
+var _ Loggable = &AccessLogEntry{}
 
-func (l *RequestStartLog) ToLog(message *LogMessage) {
+func (l *AccessLogEntry) ToLog(message *LogMessage) {
 	l.Request.ToLog(message)
 
 	message.
@@ -60,29 +79,24 @@ func (l *RequestStartLog) ToLog(message *LogMessage) {
 		Field("method", l.Method).
 		Field("uri", l.URI).
 		Field("ingress", l.Ingress).
-		Field("target", l.Target)
-
-	if len(l.Reject) != 0 {
-		message.Field("reject", l.Reject)
+		Field("target", l.Target).
+		Field("status", l.Status).
+		Field("bytes-in", l.BytesIn).
+		Field("bytes-out", l.BytesOut).
+		Field("time", l.Duration).
+		Field("time-ns", l.Duration.Nanoseconds())
+
+	if l.ConnectTime != 0 {
+		message.Field("connect-time-ns", l.ConnectTime.Nanoseconds())
 	}
-}
 
-type RequestEndLog struct {
-	Request *request
-	Time    time.Duration
-	Error   string
-}
-
-var _ Loggable = &RequestEndLog{}
-
-func (l *RequestEndLog) ToLog(message *LogMessage) {
-	l.Request.ToLog(message)
-
-	message.
-		Field("time", l.Time).
-		Field("time-ns", l.Time.Nanoseconds())
+	if len(l.TLSServerName) != 0 {
+		message.
+			Field("tls-sni", l.TLSServerName).
+			Field("tls-version", l.TLSVersion)
+	}
 
-	if len(l.Error) != 0 {
-		message.Field("error", l.Error)
+	if len(l.Reject) != 0 {
+		message.Field("reject", l.Reject)
 	}
 }